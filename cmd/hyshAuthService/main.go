@@ -16,10 +16,17 @@ import (
 
 	"github.com/handymesh/hyshAuthService/db/mongodb"
 	"github.com/handymesh/hyshAuthService/db/redis"
+	grpcServer "github.com/handymesh/hyshAuthService/grpc/server"
+	"github.com/handymesh/hyshAuthService/handlers/admin"
 	"github.com/handymesh/hyshAuthService/handlers/oauth"
 	"github.com/handymesh/hyshAuthService/handlers/session"
 	"github.com/handymesh/hyshAuthService/handlers/user"
+	"github.com/handymesh/hyshAuthService/handlers/wellknown"
+	auditModel "github.com/handymesh/hyshAuthService/models/audit"
+	outboxModel "github.com/handymesh/hyshAuthService/models/outbox"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
 	"github.com/handymesh/hyshAuthService/utils"
+	"github.com/handymesh/hyshAuthService/utils/mailer"
 )
 
 var (
@@ -36,6 +43,20 @@ func init() {
 	// Connect to DB
 	mongodb.ConnectToMongo()
 	redis.ConnectToRedis()
+
+	// Create indexes
+	if err := outboxModel.EnsureIndexes(); err != nil {
+		log.Error("Fail create outbox indexes: ", err)
+	}
+	if err := auditModel.EnsureIndexes(); err != nil {
+		log.Error("Fail create audit indexes: ", err)
+	}
+
+	// Load JWT signing keys
+	sessionModel.LoadSigningKeys()
+
+	// Select mail backend
+	mailer.Setup()
 }
 
 func main() {
@@ -68,8 +89,8 @@ func main() {
 	cors := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Admin-Key", "X-Client-Id", "X-Device-Name", "DPoP", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "WWW-Authenticate"},
 		AllowCredentials: true,
 		MaxAge:           300,
 		//Debug:            true,
@@ -86,7 +107,17 @@ func main() {
 
 	r.Mount("/users", user.Routes())
 	r.Mount("/auth", session.Routes())
+	r.Mount("/.well-known", wellknown.Routes())
 	r.Mount("/oauth", oauth.Routes())
+	r.Mount("/admin", admin.Routes())
+
+	// start gRPC-server
+	go func() {
+		err := grpcServer.Serve()
+		if err != nil {
+			log.Panic("Fail run gRPC service: ", err)
+		}
+	}()
 
 	// start HTTP-server
 	log.Info("Run services on port " + PORT)
@@ -0,0 +1,683 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: auth.proto
+
+/*
+Package auth is a generated protocol buffer package.
+
+It is generated from these files:
+
+	auth.proto
+
+It has these top-level messages:
+
+	ValidateTokenRequest
+	ValidateTokenResponse
+	Principal
+	Actor
+	GetUserRequest
+	GetUsersByIdsRequest
+	GetUsersByIdsResponse
+	User
+	CheckPermissionRequest
+	CheckPermissionResponse
+*/
+package auth
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type ValidateTokenRequest struct {
+	Token  string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Dpop   string `protobuf:"bytes,2,opt,name=dpop" json:"dpop,omitempty"`
+	Method string `protobuf:"bytes,3,opt,name=method" json:"method,omitempty"`
+	Uri    string `protobuf:"bytes,4,opt,name=uri" json:"uri,omitempty"`
+}
+
+func (m *ValidateTokenRequest) Reset()                    { *m = ValidateTokenRequest{} }
+func (m *ValidateTokenRequest) String() string            { return proto.CompactTextString(m) }
+func (*ValidateTokenRequest) ProtoMessage()               {}
+func (*ValidateTokenRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *ValidateTokenRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *ValidateTokenRequest) GetDpop() string {
+	if m != nil {
+		return m.Dpop
+	}
+	return ""
+}
+
+func (m *ValidateTokenRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *ValidateTokenRequest) GetUri() string {
+	if m != nil {
+		return m.Uri
+	}
+	return ""
+}
+
+type ValidateTokenResponse struct {
+	Active    bool       `protobuf:"varint,1,opt,name=active" json:"active,omitempty"`
+	Error     string     `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	Principal *Principal `protobuf:"bytes,3,opt,name=principal" json:"principal,omitempty"`
+}
+
+func (m *ValidateTokenResponse) Reset()                    { *m = ValidateTokenResponse{} }
+func (m *ValidateTokenResponse) String() string            { return proto.CompactTextString(m) }
+func (*ValidateTokenResponse) ProtoMessage()               {}
+func (*ValidateTokenResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ValidateTokenResponse) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *ValidateTokenResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ValidateTokenResponse) GetPrincipal() *Principal {
+	if m != nil {
+		return m.Principal
+	}
+	return nil
+}
+
+type Principal struct {
+	Subject     string   `protobuf:"bytes,1,opt,name=subject" json:"subject,omitempty"`
+	SubjectType string   `protobuf:"bytes,2,opt,name=subject_type,json=subjectType" json:"subject_type,omitempty"`
+	SessionId   string   `protobuf:"bytes,3,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	ClientId    string   `protobuf:"bytes,4,opt,name=client_id,json=clientId" json:"client_id,omitempty"`
+	Roles       []string `protobuf:"bytes,5,rep,name=roles" json:"roles,omitempty"`
+	Scopes      []string `protobuf:"bytes,6,rep,name=scopes" json:"scopes,omitempty"`
+	TokenId     string   `protobuf:"bytes,7,opt,name=token_id,json=tokenId" json:"token_id,omitempty"`
+	IssuedAt    int64    `protobuf:"varint,8,opt,name=issued_at,json=issuedAt" json:"issued_at,omitempty"`
+	ExpiresAt   int64    `protobuf:"varint,9,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	Actor       *Actor   `protobuf:"bytes,10,opt,name=actor" json:"actor,omitempty"`
+}
+
+func (m *Principal) Reset()                    { *m = Principal{} }
+func (m *Principal) String() string            { return proto.CompactTextString(m) }
+func (*Principal) ProtoMessage()               {}
+func (*Principal) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *Principal) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *Principal) GetSubjectType() string {
+	if m != nil {
+		return m.SubjectType
+	}
+	return ""
+}
+
+func (m *Principal) GetSessionId() string {
+	if m != nil {
+		return m.SessionId
+	}
+	return ""
+}
+
+func (m *Principal) GetClientId() string {
+	if m != nil {
+		return m.ClientId
+	}
+	return ""
+}
+
+func (m *Principal) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *Principal) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *Principal) GetTokenId() string {
+	if m != nil {
+		return m.TokenId
+	}
+	return ""
+}
+
+func (m *Principal) GetIssuedAt() int64 {
+	if m != nil {
+		return m.IssuedAt
+	}
+	return 0
+}
+
+func (m *Principal) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *Principal) GetActor() *Actor {
+	if m != nil {
+		return m.Actor
+	}
+	return nil
+}
+
+type Actor struct {
+	Subject     string `protobuf:"bytes,1,opt,name=subject" json:"subject,omitempty"`
+	SubjectType string `protobuf:"bytes,2,opt,name=subject_type,json=subjectType" json:"subject_type,omitempty"`
+	Actor       *Actor `protobuf:"bytes,3,opt,name=actor" json:"actor,omitempty"`
+}
+
+func (m *Actor) Reset()                    { *m = Actor{} }
+func (m *Actor) String() string            { return proto.CompactTextString(m) }
+func (*Actor) ProtoMessage()               {}
+func (*Actor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *Actor) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *Actor) GetSubjectType() string {
+	if m != nil {
+		return m.SubjectType
+	}
+	return ""
+}
+
+func (m *Actor) GetActor() *Actor {
+	if m != nil {
+		return m.Actor
+	}
+	return nil
+}
+
+type GetUserRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *GetUserRequest) Reset()                    { *m = GetUserRequest{} }
+func (m *GetUserRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetUserRequest) ProtoMessage()               {}
+func (*GetUserRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *GetUserRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type GetUsersByIdsRequest struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids" json:"ids,omitempty"`
+}
+
+func (m *GetUsersByIdsRequest) Reset()                    { *m = GetUsersByIdsRequest{} }
+func (m *GetUsersByIdsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetUsersByIdsRequest) ProtoMessage()               {}
+func (*GetUsersByIdsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *GetUsersByIdsRequest) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type GetUsersByIdsResponse struct {
+	Users []*User `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+}
+
+func (m *GetUsersByIdsResponse) Reset()                    { *m = GetUsersByIdsResponse{} }
+func (m *GetUsersByIdsResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetUsersByIdsResponse) ProtoMessage()               {}
+func (*GetUsersByIdsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *GetUsersByIdsResponse) GetUsers() []*User {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+type User struct {
+	Id            string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Email         string   `protobuf:"bytes,2,opt,name=email" json:"email,omitempty"`
+	EmailVerified bool     `protobuf:"varint,3,opt,name=email_verified,json=emailVerified" json:"email_verified,omitempty"`
+	Fullname      string   `protobuf:"bytes,4,opt,name=fullname" json:"fullname,omitempty"`
+	Locale        string   `protobuf:"bytes,5,opt,name=locale" json:"locale,omitempty"`
+	Roles         []string `protobuf:"bytes,6,rep,name=roles" json:"roles,omitempty"`
+	Status        string   `protobuf:"bytes,7,opt,name=status" json:"status,omitempty"`
+	CreatedAt     int64    `protobuf:"varint,8,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	UpdatedAt     int64    `protobuf:"varint,9,opt,name=updated_at,json=updatedAt" json:"updated_at,omitempty"`
+}
+
+func (m *User) Reset()                    { *m = User{} }
+func (m *User) String() string            { return proto.CompactTextString(m) }
+func (*User) ProtoMessage()               {}
+func (*User) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *User) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *User) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *User) GetEmailVerified() bool {
+	if m != nil {
+		return m.EmailVerified
+	}
+	return false
+}
+
+func (m *User) GetFullname() string {
+	if m != nil {
+		return m.Fullname
+	}
+	return ""
+}
+
+func (m *User) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *User) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *User) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *User) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *User) GetUpdatedAt() int64 {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return 0
+}
+
+type CheckPermissionRequest struct {
+	Token  string   `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Dpop   string   `protobuf:"bytes,2,opt,name=dpop" json:"dpop,omitempty"`
+	Method string   `protobuf:"bytes,3,opt,name=method" json:"method,omitempty"`
+	Uri    string   `protobuf:"bytes,4,opt,name=uri" json:"uri,omitempty"`
+	Scopes []string `protobuf:"bytes,5,rep,name=scopes" json:"scopes,omitempty"`
+	Roles  []string `protobuf:"bytes,6,rep,name=roles" json:"roles,omitempty"`
+}
+
+func (m *CheckPermissionRequest) Reset()                    { *m = CheckPermissionRequest{} }
+func (m *CheckPermissionRequest) String() string            { return proto.CompactTextString(m) }
+func (*CheckPermissionRequest) ProtoMessage()               {}
+func (*CheckPermissionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *CheckPermissionRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *CheckPermissionRequest) GetDpop() string {
+	if m != nil {
+		return m.Dpop
+	}
+	return ""
+}
+
+func (m *CheckPermissionRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *CheckPermissionRequest) GetUri() string {
+	if m != nil {
+		return m.Uri
+	}
+	return ""
+}
+
+func (m *CheckPermissionRequest) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *CheckPermissionRequest) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+type CheckPermissionResponse struct {
+	Allowed   bool       `protobuf:"varint,1,opt,name=allowed" json:"allowed,omitempty"`
+	Reason    string     `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+	Principal *Principal `protobuf:"bytes,3,opt,name=principal" json:"principal,omitempty"`
+}
+
+func (m *CheckPermissionResponse) Reset()                    { *m = CheckPermissionResponse{} }
+func (m *CheckPermissionResponse) String() string            { return proto.CompactTextString(m) }
+func (*CheckPermissionResponse) ProtoMessage()               {}
+func (*CheckPermissionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *CheckPermissionResponse) GetAllowed() bool {
+	if m != nil {
+		return m.Allowed
+	}
+	return false
+}
+
+func (m *CheckPermissionResponse) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *CheckPermissionResponse) GetPrincipal() *Principal {
+	if m != nil {
+		return m.Principal
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ValidateTokenRequest)(nil), "auth.ValidateTokenRequest")
+	proto.RegisterType((*ValidateTokenResponse)(nil), "auth.ValidateTokenResponse")
+	proto.RegisterType((*Principal)(nil), "auth.Principal")
+	proto.RegisterType((*Actor)(nil), "auth.Actor")
+	proto.RegisterType((*GetUserRequest)(nil), "auth.GetUserRequest")
+	proto.RegisterType((*GetUsersByIdsRequest)(nil), "auth.GetUsersByIdsRequest")
+	proto.RegisterType((*GetUsersByIdsResponse)(nil), "auth.GetUsersByIdsResponse")
+	proto.RegisterType((*User)(nil), "auth.User")
+	proto.RegisterType((*CheckPermissionRequest)(nil), "auth.CheckPermissionRequest")
+	proto.RegisterType((*CheckPermissionResponse)(nil), "auth.CheckPermissionResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Auth service
+
+type AuthClient interface {
+	ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error)
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	GetUsersByIds(ctx context.Context, in *GetUsersByIdsRequest, opts ...grpc.CallOption) (*GetUsersByIdsResponse, error)
+	CheckPermission(ctx context.Context, in *CheckPermissionRequest, opts ...grpc.CallOption) (*CheckPermissionResponse, error)
+}
+
+type authClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAuthClient(cc *grpc.ClientConn) AuthClient {
+	return &authClient{cc}
+}
+
+func (c *authClient) ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error) {
+	out := new(ValidateTokenResponse)
+	err := grpc.Invoke(ctx, "/auth.Auth/ValidateToken", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := grpc.Invoke(ctx, "/auth.Auth/GetUser", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) GetUsersByIds(ctx context.Context, in *GetUsersByIdsRequest, opts ...grpc.CallOption) (*GetUsersByIdsResponse, error) {
+	out := new(GetUsersByIdsResponse)
+	err := grpc.Invoke(ctx, "/auth.Auth/GetUsersByIds", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) CheckPermission(ctx context.Context, in *CheckPermissionRequest, opts ...grpc.CallOption) (*CheckPermissionResponse, error) {
+	out := new(CheckPermissionResponse)
+	err := grpc.Invoke(ctx, "/auth.Auth/CheckPermission", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Auth service
+
+type AuthServer interface {
+	ValidateToken(context.Context, *ValidateTokenRequest) (*ValidateTokenResponse, error)
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	GetUsersByIds(context.Context, *GetUsersByIdsRequest) (*GetUsersByIdsResponse, error)
+	CheckPermission(context.Context, *CheckPermissionRequest) (*CheckPermissionResponse, error)
+}
+
+func RegisterAuthServer(s *grpc.Server, srv AuthServer) {
+	s.RegisterService(&_Auth_serviceDesc, srv)
+}
+
+func _Auth_ValidateToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).ValidateToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/auth.Auth/ValidateToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).ValidateToken(ctx, req.(*ValidateTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/auth.Auth/GetUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_GetUsersByIds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsersByIdsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).GetUsersByIds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/auth.Auth/GetUsersByIds",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).GetUsersByIds(ctx, req.(*GetUsersByIdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_CheckPermission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckPermissionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).CheckPermission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/auth.Auth/CheckPermission",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).CheckPermission(ctx, req.(*CheckPermissionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Auth_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "auth.Auth",
+	HandlerType: (*AuthServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateToken",
+			Handler:    _Auth_ValidateToken_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _Auth_GetUser_Handler,
+		},
+		{
+			MethodName: "GetUsersByIds",
+			Handler:    _Auth_GetUsersByIds_Handler,
+		},
+		{
+			MethodName: "CheckPermission",
+			Handler:    _Auth_CheckPermission_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "auth.proto",
+}
+
+func init() { proto.RegisterFile("auth.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 648 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0xcf, 0x6e, 0xd3, 0x4e,
+	0x10, 0x6e, 0xfe, 0x38, 0xb1, 0x27, 0xbf, 0xfe, 0xd1, 0x2a, 0xed, 0xcf, 0xa4, 0x54, 0x0a, 0x96,
+	0x90, 0x7a, 0x69, 0x0f, 0xe5, 0xc4, 0x31, 0x70, 0x40, 0xe5, 0x54, 0x59, 0xa5, 0xd7, 0x68, 0x6b,
+	0x4f, 0xc9, 0xb6, 0x8e, 0xd7, 0xec, 0xae, 0x0b, 0xe5, 0x51, 0xb8, 0xf0, 0x02, 0xbc, 0x1d, 0x2f,
+	0x80, 0x76, 0x77, 0x9c, 0x26, 0x21, 0x3d, 0x20, 0xc4, 0x6d, 0xbe, 0x6f, 0x26, 0xfe, 0x66, 0x66,
+	0xbf, 0xdd, 0x00, 0xf0, 0xda, 0xcc, 0x4e, 0x2b, 0x25, 0x8d, 0x64, 0x5d, 0x1b, 0x27, 0xb7, 0x30,
+	0xbc, 0xe2, 0x85, 0xc8, 0xb9, 0xc1, 0x4b, 0x79, 0x87, 0x65, 0x8a, 0x9f, 0x6a, 0xd4, 0x86, 0x0d,
+	0x21, 0x30, 0x16, 0xc7, 0xad, 0x71, 0xeb, 0x38, 0x4a, 0x3d, 0x60, 0x0c, 0xba, 0x79, 0x25, 0xab,
+	0xb8, 0xed, 0x48, 0x17, 0xb3, 0x03, 0xe8, 0xcd, 0xd1, 0xcc, 0x64, 0x1e, 0x77, 0x1c, 0x4b, 0x88,
+	0xed, 0x41, 0xa7, 0x56, 0x22, 0xee, 0x3a, 0xd2, 0x86, 0x89, 0x81, 0xfd, 0x35, 0x2d, 0x5d, 0xc9,
+	0x52, 0xa3, 0xfd, 0x04, 0xcf, 0x8c, 0xb8, 0x47, 0xa7, 0x16, 0xa6, 0x84, 0x6c, 0x13, 0xa8, 0x94,
+	0x54, 0xa4, 0xe7, 0x01, 0x3b, 0x81, 0xa8, 0x52, 0xa2, 0xcc, 0x44, 0xc5, 0x0b, 0xa7, 0x39, 0x38,
+	0xdb, 0x3d, 0x75, 0x83, 0x5d, 0x34, 0x74, 0xfa, 0x58, 0x91, 0xfc, 0x68, 0x43, 0xb4, 0x48, 0xb0,
+	0x18, 0xfa, 0xba, 0xbe, 0xbe, 0xc5, 0xcc, 0xd0, 0x64, 0x0d, 0x64, 0x2f, 0xe0, 0x3f, 0x0a, 0xa7,
+	0xe6, 0xa1, 0x42, 0xd2, 0x1c, 0x10, 0x77, 0xf9, 0x50, 0x21, 0x3b, 0x02, 0xd0, 0xa8, 0xb5, 0x90,
+	0xe5, 0x54, 0x34, 0xe3, 0x46, 0xc4, 0x9c, 0xe7, 0xec, 0x10, 0xa2, 0xac, 0x10, 0x58, 0x1a, 0x9b,
+	0xf5, 0x73, 0x87, 0x9e, 0x38, 0xcf, 0xed, 0x2c, 0x4a, 0x16, 0xa8, 0xe3, 0x60, 0xdc, 0xb1, 0xb3,
+	0x38, 0x60, 0x27, 0xd7, 0x99, 0xac, 0x50, 0xc7, 0x3d, 0x47, 0x13, 0x62, 0xcf, 0x20, 0x74, 0x1b,
+	0xb7, 0x5f, 0xea, 0xfb, 0x3e, 0x1d, 0xf6, 0x2a, 0x42, 0xeb, 0x1a, 0xf3, 0x29, 0x37, 0x71, 0x38,
+	0x6e, 0x1d, 0x77, 0xd2, 0xd0, 0x13, 0x13, 0x63, 0x3b, 0xc4, 0x2f, 0x95, 0x50, 0xa8, 0x6d, 0x36,
+	0x72, 0xd9, 0x88, 0x98, 0x89, 0x9d, 0x31, 0xe0, 0x99, 0x91, 0x2a, 0x06, 0xb7, 0xb6, 0x81, 0x5f,
+	0xdb, 0xc4, 0x52, 0xa9, 0xcf, 0x24, 0x1f, 0x21, 0x70, 0xf8, 0xef, 0x36, 0xb5, 0x10, 0xea, 0x3c,
+	0x29, 0x34, 0x86, 0x9d, 0x77, 0x68, 0x3e, 0x68, 0x54, 0x8d, 0xe7, 0x76, 0xa0, 0x2d, 0x72, 0x12,
+	0x6b, 0x8b, 0x3c, 0x39, 0x86, 0x21, 0x55, 0xe8, 0x37, 0x0f, 0xe7, 0xb9, 0x6e, 0xea, 0xf6, 0xa0,
+	0x23, 0x72, 0x1d, 0xb7, 0xdc, 0xc6, 0x6c, 0x98, 0xbc, 0x86, 0xfd, 0xb5, 0x4a, 0x72, 0xd6, 0x18,
+	0x82, 0xda, 0xb2, 0xae, 0x78, 0x70, 0x06, 0xbe, 0x0f, 0x27, 0xea, 0x13, 0xc9, 0xcf, 0x16, 0x74,
+	0x2d, 0x5e, 0x57, 0x77, 0xe6, 0x9b, 0x73, 0x51, 0x2c, 0xcc, 0x67, 0x01, 0x7b, 0x09, 0x3b, 0x2e,
+	0x98, 0xde, 0xa3, 0x12, 0x37, 0x02, 0xbd, 0x0d, 0xc2, 0x74, 0xdb, 0xb1, 0x57, 0x44, 0xb2, 0x11,
+	0x84, 0x37, 0x75, 0x51, 0x94, 0x7c, 0x8e, 0x8d, 0x13, 0x1a, 0x6c, 0xcf, 0xbc, 0x90, 0x19, 0x2f,
+	0x30, 0x0e, 0xfc, 0x85, 0xf1, 0xe8, 0xd1, 0x21, 0xbd, 0x75, 0x87, 0x18, 0x6e, 0x6a, 0x4d, 0x3e,
+	0x20, 0x64, 0x4f, 0x3a, 0x53, 0xc8, 0xcd, 0xb2, 0x0f, 0x22, 0x62, 0xbc, 0x11, 0xea, 0x2a, 0x6f,
+	0xd2, 0x64, 0x04, 0x62, 0x26, 0x26, 0xf9, 0xd6, 0x82, 0x83, 0xb7, 0x33, 0xcc, 0xee, 0x2e, 0x50,
+	0xcd, 0x85, 0x33, 0xf0, 0x3f, 0xbc, 0xf9, 0x4b, 0x36, 0x0f, 0x56, 0x6c, 0xbe, 0x71, 0xe4, 0xe4,
+	0x2b, 0xfc, 0xff, 0x5b, 0x6f, 0x74, 0x9e, 0x31, 0xf4, 0x79, 0x51, 0xc8, 0xcf, 0x98, 0xd3, 0x53,
+	0xd1, 0x40, 0x2b, 0xa1, 0x90, 0x6b, 0x59, 0x52, 0x8b, 0x84, 0xfe, 0xf0, 0xb5, 0x38, 0xfb, 0xde,
+	0x86, 0xee, 0xa4, 0x36, 0x33, 0xf6, 0x1e, 0xb6, 0x57, 0x1e, 0x2b, 0x36, 0xf2, 0xbf, 0xda, 0xf4,
+	0x5a, 0x8e, 0x0e, 0x37, 0xe6, 0x7c, 0xcf, 0xc9, 0x16, 0x3b, 0x81, 0x3e, 0xd9, 0x93, 0x0d, 0x7d,
+	0xe5, 0xaa, 0xf3, 0x47, 0x4b, 0xbe, 0x4c, 0xb6, 0xac, 0xf4, 0x8a, 0x9b, 0x1b, 0xe9, 0x4d, 0x97,
+	0x61, 0x74, 0xb8, 0x31, 0xb7, 0x90, 0xbe, 0x80, 0xdd, 0xb5, 0x5d, 0xb2, 0xe7, 0xfe, 0x17, 0x9b,
+	0x8f, 0x7f, 0x74, 0xf4, 0x44, 0xb6, 0xf9, 0xe2, 0x75, 0xcf, 0xfd, 0x7d, 0xbc, 0xfa, 0x35, 0x00,
+	0x7f, 0xd6, 0x44, 0x80, 0x4c, 0x06, 0x00, 0x00,
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+var (
+	log = logrus.New()
+
+	GrpcClient *grpc.ClientConn
+	dialOnce   sync.Once
+)
+
+func init() {
+	// Logging =================================================================
+	// Setup the logger backend using Sirupsen/logrus and configure
+	// it to use a custom JSONFormatter. See the logrus docs for how to
+	// configure the backend at github.com/Sirupsen/logrus
+	log.Formatter = new(logrus.JSONFormatter)
+}
+
+// GetConnClient returns the connection to the mail service,
+// dialed on first use so that other mail backends don't need it
+func GetConnClient() *grpc.ClientConn {
+	dialOnce.Do(func() {
+		// Get configuration
+		API_MAIL_ADDRESS := utils.Getenv("API_MAIL_ADDRESS", "localhost:50051")
+
+		// Connect to API by gRPC
+		var err error
+		GrpcClient, err = grpc.Dial(API_MAIL_ADDRESS, grpc.WithInsecure(), grpc.WithUnaryInterceptor(propagate))
+		if err != nil {
+			log.Error("did not connect: ", err)
+			return
+		}
+
+		log.Info("Success connect to Mail API by gRPC")
+	})
+
+	return GrpcClient
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	METADATA_REQUEST_ID = "x-request-id"
+)
+
+// propagate traces an outgoing call and sends the chi request id
+// and the tracing context of ctx as metadata
+func propagate(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, method, ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+
+	if requestID := middleware.GetReqID(ctx); requestID != "" {
+		md.Set(METADATA_REQUEST_ID, requestID)
+		span.SetTag("request_id", requestID)
+	}
+
+	err := span.Tracer().Inject(span.Context(), opentracing.TextMap, metadataCarrier(md))
+	if err != nil {
+		log.Warn("Fail inject tracing context: ", err)
+	}
+
+	err = invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+	if err != nil {
+		ext.Error.Set(span, true)
+	}
+
+	return err
+}
+
+// metadataCarrier writes the tracing context into gRPC metadata
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Set(key, val string) {
+	key = strings.ToLower(key)
+	c[key] = append(c[key], val)
+}
+
+func (c metadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for key, values := range c {
+		for _, val := range values {
+			if err := handler(key, val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -5,9 +5,11 @@
 Package mail is a generated protocol buffer package.
 
 It is generated from these files:
+
 	mail.proto
 
 It has these top-level messages:
+
 	MailRequest
 	MailResponse
 */
@@ -33,10 +35,38 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type Priority int32
+
+const (
+	Priority_NORMAL Priority = 0
+	Priority_HIGH   Priority = 1
+	Priority_LOW    Priority = 2
+)
+
+var Priority_name = map[int32]string{
+	0: "NORMAL",
+	1: "HIGH",
+	2: "LOW",
+}
+var Priority_value = map[string]int32{
+	"NORMAL": 0,
+	"HIGH":   1,
+	"LOW":    2,
+}
+
+func (x Priority) String() string {
+	return proto.EnumName(Priority_name, int32(x))
+}
+func (Priority) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
 type MailRequest struct {
-	Template string `protobuf:"bytes,1,opt,name=template" json:"template,omitempty"`
-	Mail     string `protobuf:"bytes,2,opt,name=mail" json:"mail,omitempty"`
-	Url      string `protobuf:"bytes,3,opt,name=url" json:"url,omitempty"`
+	Template  string            `protobuf:"bytes,1,opt,name=template" json:"template,omitempty"`
+	Mail      string            `protobuf:"bytes,2,opt,name=mail" json:"mail,omitempty"`
+	Url       string            `protobuf:"bytes,3,opt,name=url" json:"url,omitempty"`
+	Locale    string            `protobuf:"bytes,4,opt,name=locale" json:"locale,omitempty"`
+	Variables map[string]string `protobuf:"bytes,5,rep,name=variables" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RequestId string            `protobuf:"bytes,6,opt,name=request_id,json=requestId" json:"request_id,omitempty"`
+	Priority  Priority          `protobuf:"varint,7,opt,name=priority,enum=mail.Priority" json:"priority,omitempty"`
 }
 
 func (m *MailRequest) Reset()                    { *m = MailRequest{} }
@@ -65,6 +95,34 @@ func (m *MailRequest) GetUrl() string {
 	return ""
 }
 
+func (m *MailRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *MailRequest) GetVariables() map[string]string {
+	if m != nil {
+		return m.Variables
+	}
+	return nil
+}
+
+func (m *MailRequest) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *MailRequest) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_NORMAL
+}
+
 type MailResponse struct {
 	Success bool `protobuf:"varint,1,opt,name=success" json:"success,omitempty"`
 }
@@ -84,6 +142,7 @@ func (m *MailResponse) GetSuccess() bool {
 func init() {
 	proto.RegisterType((*MailRequest)(nil), "mail.MailRequest")
 	proto.RegisterType((*MailResponse)(nil), "mail.MailResponse")
+	proto.RegisterEnum("mail.Priority", Priority_name, Priority_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -161,16 +220,25 @@ var _Mail_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("mail.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 164 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xca, 0x4d, 0xcc, 0xcc,
-	0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x01, 0xb1, 0x95, 0xfc, 0xb9, 0xb8, 0x7d, 0x13,
-	0x33, 0x73, 0x82, 0x52, 0x0b, 0x4b, 0x53, 0x8b, 0x4b, 0x84, 0xa4, 0xb8, 0x38, 0x4a, 0x52, 0x73,
-	0x0b, 0x72, 0x12, 0x4b, 0x52, 0x25, 0x18, 0x15, 0x18, 0x35, 0x38, 0x83, 0xe0, 0x7c, 0x21, 0x21,
-	0x2e, 0xb0, 0x16, 0x09, 0x26, 0xb0, 0x38, 0x98, 0x2d, 0x24, 0xc0, 0xc5, 0x5c, 0x5a, 0x94, 0x23,
-	0xc1, 0x0c, 0x16, 0x02, 0x31, 0x95, 0x34, 0xb8, 0x78, 0x20, 0x06, 0x16, 0x17, 0xe4, 0xe7, 0x15,
-	0xa7, 0x0a, 0x49, 0x70, 0xb1, 0x17, 0x97, 0x26, 0x27, 0xa7, 0x16, 0x17, 0x83, 0x0d, 0xe4, 0x08,
-	0x82, 0x71, 0x8d, 0xac, 0xb9, 0x58, 0x40, 0x2a, 0x85, 0x8c, 0xb9, 0x38, 0x82, 0x53, 0xf3, 0x52,
-	0xc0, 0x6c, 0x41, 0x3d, 0xb0, 0x0b, 0x91, 0x9c, 0x24, 0x25, 0x84, 0x2c, 0x04, 0x31, 0x54, 0x89,
-	0x21, 0x89, 0x0d, 0xec, 0x09, 0x63, 0x40, 0x00, 0x00, 0x00, 0xff, 0xff, 0xa4, 0xfe, 0xf7, 0x5a,
-	0xd2, 0x00, 0x00, 0x00,
+	// 315 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0x4f, 0x6b, 0xf2, 0x40,
+	0x10, 0xc6, 0xcd, 0x1f, 0xe3, 0x3a, 0xbe, 0x48, 0xde, 0xa1, 0x94, 0x45, 0x28, 0x04, 0x4f, 0xa9,
+	0x07, 0x0f, 0x7a, 0x29, 0x6d, 0x29, 0xf4, 0x50, 0xaa, 0xa0, 0xb5, 0x6c, 0xa1, 0x3d, 0x96, 0x55,
+	0xf7, 0xb0, 0x74, 0x35, 0xe9, 0xee, 0x46, 0xf0, 0x5b, 0xf5, 0x23, 0x96, 0x6c, 0xa2, 0xb5, 0xf4,
+	0xf6, 0x3c, 0xbf, 0xcc, 0xcc, 0x33, 0x93, 0x05, 0xd8, 0x70, 0xa9, 0x86, 0xb9, 0xce, 0x6c, 0x86,
+	0x61, 0xa9, 0xfb, 0x5f, 0x3e, 0x74, 0xe6, 0x5c, 0x2a, 0x26, 0x3e, 0x0b, 0x61, 0x2c, 0xf6, 0x80,
+	0x58, 0xb1, 0xc9, 0x15, 0xb7, 0x82, 0x7a, 0x89, 0x97, 0xb6, 0xd9, 0xd1, 0x23, 0x82, 0xeb, 0xa1,
+	0xbe, 0xe3, 0x4e, 0x63, 0x0c, 0x41, 0xa1, 0x15, 0x0d, 0x1c, 0x2a, 0x25, 0x9e, 0x43, 0xa4, 0xb2,
+	0x15, 0x57, 0x82, 0x86, 0x0e, 0xd6, 0x0e, 0xef, 0xa0, 0xbd, 0xe3, 0x5a, 0xf2, 0xa5, 0x12, 0x86,
+	0x36, 0x93, 0x20, 0xed, 0x8c, 0x92, 0xa1, 0xdb, 0xe7, 0x24, 0x7f, 0xf8, 0x7a, 0x28, 0x79, 0xd8,
+	0x5a, 0xbd, 0x67, 0x3f, 0x2d, 0x78, 0x01, 0xa0, 0xab, 0xa2, 0x77, 0xb9, 0xa6, 0x91, 0x9b, 0xdd,
+	0xae, 0xc9, 0x74, 0x8d, 0x03, 0x20, 0xb9, 0x96, 0x99, 0x96, 0x76, 0x4f, 0x5b, 0x89, 0x97, 0x76,
+	0x47, 0xdd, 0x6a, 0xfa, 0x73, 0x4d, 0xd9, 0xf1, 0x7b, 0xef, 0x16, 0xba, 0xbf, 0x73, 0xca, 0x33,
+	0x3e, 0xc4, 0xbe, 0xbe, 0xb8, 0x94, 0x78, 0x06, 0xcd, 0x1d, 0x57, 0x85, 0xa8, 0xaf, 0xad, 0xcc,
+	0xb5, 0x7f, 0xe5, 0xf5, 0x53, 0xf8, 0x57, 0x6d, 0x6c, 0xf2, 0x6c, 0x6b, 0x04, 0x52, 0x68, 0x99,
+	0x62, 0xb5, 0x12, 0xc6, 0xb8, 0x7e, 0xc2, 0x0e, 0x76, 0x70, 0x09, 0xe4, 0x90, 0x8e, 0x00, 0xd1,
+	0xd3, 0x82, 0xcd, 0xef, 0x67, 0x71, 0x03, 0x09, 0x84, 0x93, 0xe9, 0xe3, 0x24, 0xf6, 0xb0, 0x05,
+	0xc1, 0x6c, 0xf1, 0x16, 0xfb, 0xa3, 0x1b, 0x08, 0xcb, 0xa1, 0x38, 0x06, 0xf2, 0x22, 0xb6, 0x6b,
+	0xa7, 0xff, 0xff, 0xf9, 0x3d, 0x3d, 0x3c, 0x45, 0x55, 0x7e, 0xbf, 0xb1, 0x8c, 0xdc, 0x8b, 0x8e,
+	0xbf, 0x07, 0x00, 0xc7, 0x4f, 0xa1, 0x7b, 0xdf, 0x01, 0x00, 0x00,
 }
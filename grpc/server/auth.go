@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/handymesh/hyshAuthService/grpc/auth"
+	"github.com/handymesh/hyshAuthService/middleware"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	userModel "github.com/handymesh/hyshAuthService/models/user"
+)
+
+const (
+	// maximum number of users looked up by one GetUsersByIds call
+	MAX_USERS_BY_IDS = 100
+)
+
+// authServer implements the Auth gRPC service
+type authServer struct{}
+
+// ValidateToken tells whether an access token is active and who it identifies.
+// An inactive token is not an error, the response carries the reason.
+func (s *authServer) ValidateToken(ctx context.Context, in *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	claims, err := validateToken(in.Token, in.Dpop, in.Method, in.Uri)
+	if err != nil {
+		return &pb.ValidateTokenResponse{Active: false, Error: err.Error()}, nil
+	}
+
+	return &pb.ValidateTokenResponse{
+		Active:    true,
+		Principal: newPrincipal(claims.Principal()),
+	}, nil
+}
+
+// CheckPermission tells whether an access token grants all requested scopes
+// and its subject has one of the requested roles
+func (s *authServer) CheckPermission(ctx context.Context, in *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	claims, err := validateToken(in.Token, in.Dpop, in.Method, in.Uri)
+	if err != nil {
+		return &pb.CheckPermissionResponse{Allowed: false, Reason: err.Error()}, nil
+	}
+
+	principal := claims.Principal()
+	response := &pb.CheckPermissionResponse{Principal: newPrincipal(principal)}
+
+	for _, scope := range in.Scopes {
+		if !principal.HasScope(scope) {
+			response.Reason = "insufficient scope"
+			return response, nil
+		}
+	}
+
+	if len(in.Roles) > 0 && !hasAnyRole(principal, in.Roles) {
+		response.Reason = "insufficient role"
+		return response, nil
+	}
+
+	response.Allowed = true
+	return response, nil
+}
+
+// GetUser returns the user with the given id
+func (s *authServer) GetUser(ctx context.Context, in *pb.GetUserRequest) (*pb.User, error) {
+	if !primitive.IsValidObjectID(in.Id) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	user, err := userModel.FindByID(in.Id)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		log.Error("Fail find user: ", err)
+		return nil, status.Error(codes.Internal, "failed to find user")
+	}
+
+	return newUser(user), nil
+}
+
+// GetUsersByIds returns the users with the given ids in the order of the request
+func (s *authServer) GetUsersByIds(ctx context.Context, in *pb.GetUsersByIdsRequest) (*pb.GetUsersByIdsResponse, error) {
+	if len(in.Ids) > MAX_USERS_BY_IDS {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ids are allowed", MAX_USERS_BY_IDS)
+	}
+
+	users, err := userModel.FindByIDs(in.Ids)
+	if err != nil {
+		log.Error("Fail find users: ", err)
+		return nil, status.Error(codes.Internal, "failed to find users")
+	}
+
+	byId := make(map[string]*userModel.User, len(users))
+	for i := range users {
+		byId[users[i].Id] = &users[i]
+	}
+
+	response := &pb.GetUsersByIdsResponse{Users: []*pb.User{}}
+	for _, id := range in.Ids {
+		if user, ok := byId[id]; ok {
+			response.Users = append(response.Users, newUser(user))
+			delete(byId, id)
+		}
+	}
+
+	return response, nil
+}
+
+// validateToken verifies an access token the way middleware.Authenticate does.
+// A token bound to a DPoP key needs a proof for the given method and URI.
+func validateToken(token string, proof string, method string, uri string) (*sessionModel.Claims, error) {
+	if token == "" {
+		return nil, middleware.ErrNotAuth
+	}
+
+	claims, err := sessionModel.ParseAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	jkt := claims.KeyThumbprint()
+	if jkt == "" {
+		return claims, nil
+	}
+	if proof == "" {
+		return nil, middleware.ErrTokenBound
+	}
+
+	proofJkt, err := sessionModel.VerifyDPoPProof(proof, method, uri, token)
+	if err != nil {
+		return nil, err
+	}
+	if proofJkt != jkt {
+		return nil, sessionModel.ErrDPoPProofInvalid
+	}
+
+	return claims, nil
+}
+
+func hasAnyRole(principal *sessionModel.Principal, roles []string) bool {
+	for _, role := range roles {
+		if principal.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+func newPrincipal(principal *sessionModel.Principal) *pb.Principal {
+	return &pb.Principal{
+		Subject:     principal.Subject,
+		SubjectType: principal.SubjectType,
+		SessionId:   principal.SessionID,
+		ClientId:    principal.ClientID,
+		Roles:       principal.Roles,
+		Scopes:      principal.Scopes,
+		TokenId:     principal.TokenID,
+		IssuedAt:    principal.IssuedAt.Unix(),
+		ExpiresAt:   principal.ExpiresAt.Unix(),
+		Actor:       newActor(principal.Actor),
+	}
+}
+
+func newActor(actor *sessionModel.Actor) *pb.Actor {
+	if actor == nil {
+		return nil
+	}
+
+	return &pb.Actor{
+		Subject:     actor.Subject,
+		SubjectType: actor.SubjectType,
+		Actor:       newActor(actor.Act),
+	}
+}
+
+func newUser(user *userModel.User) *pb.User {
+	response := &pb.User{
+		Id:            user.Id,
+		EmailVerified: user.EmailVerified,
+		Fullname:      user.Fullname,
+		Locale:        user.Locale,
+		Roles:         user.GetRoles(),
+		Status:        user.Status,
+	}
+	if user.Email != nil {
+		response.Email = *user.Email
+	}
+	if user.CreatedAt != nil {
+		response.CreatedAt = user.CreatedAt.Unix()
+	}
+	if user.UpdatedAt != nil {
+		response.UpdatedAt = user.UpdatedAt.Unix()
+	}
+
+	return response
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/handymesh/hyshAuthService/middleware"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+const (
+	SCOPE_USERS_READ = "users:read"
+)
+
+// methodScopes are the scopes a caller needs besides a valid client token
+var methodScopes = map[string]string{
+	"/auth.Auth/GetUser":       SCOPE_USERS_READ,
+	"/auth.Auth/GetUsersByIds": SCOPE_USERS_READ,
+}
+
+type principalCtxKey struct{}
+
+// authenticate lets through services calling with an access token of the
+// client credentials grant in the "authorization" metadata
+func authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	token := bearerToken(ctx)
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, middleware.ErrNotAuth.Error())
+	}
+
+	claims, err := sessionModel.ParseAccessToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	// A DPoP proof can't be bound to a gRPC call
+	if claims.KeyThumbprint() != "" {
+		return nil, status.Error(codes.Unauthenticated, middleware.ErrTokenBound.Error())
+	}
+
+	principal := claims.Principal()
+	if !principal.IsClient() {
+		return nil, status.Error(codes.PermissionDenied, "client token required")
+	}
+
+	if scope, ok := methodScopes[info.FullMethod]; ok && !principal.HasScope(scope) {
+		return nil, status.Error(codes.PermissionDenied, "insufficient scope")
+	}
+
+	ctx = context.WithValue(ctx, principalCtxKey{}, principal)
+	return handler(ctx, req)
+}
+
+// bearerToken reads the token from the "authorization" metadata.
+// A token without scheme is taken as a bearer token.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(values[0]), " ", 2)
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	if !strings.EqualFold(parts[0], middleware.SCHEME_BEARER) {
+		return ""
+	}
+
+	return strings.TrimSpace(parts[1])
+}
@@ -1,16 +1,17 @@
 package server
 
 import (
-	"github.com/handymesh/hyshAuthService/utils"
+	"net"
+
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+
+	pb "github.com/handymesh/hyshAuthService/grpc/auth"
+	"github.com/handymesh/hyshAuthService/utils"
 )
 
 var (
 	log = logrus.New()
-
-	err        error
-	GrpcClient *grpc.ClientConn
 )
 
 func init() {
@@ -21,19 +22,19 @@ func init() {
 	log.Formatter = new(logrus.JSONFormatter)
 }
 
-func init() {
+// Serve runs the Auth gRPC service on GRPC_PORT
+func Serve() error {
 	// Get configuration
-	API_MAIL_ADDRESS := utils.Getenv("API_MAIL_ADDRESS", "localhost:50051")
+	GRPC_PORT := utils.Getenv("GRPC_PORT", "4071")
 
-	// Connect to API by gRPC
-	GrpcClient, err = grpc.Dial(API_MAIL_ADDRESS, grpc.WithInsecure())
+	lis, err := net.Listen("tcp", ":"+GRPC_PORT)
 	if err != nil {
-		log.Error("did not connect: ", err)
+		return err
 	}
 
-	log.Info("Success connect to Mail API by gRPC")
-}
+	s := grpc.NewServer(grpc.UnaryInterceptor(authenticate))
+	pb.RegisterAuthServer(s, &authServer{})
 
-func GetConnClient() *grpc.ClientConn {
-	return GrpcClient
+	log.Info("Run gRPC service on port " + GRPC_PORT)
+	return s.Serve(lis)
 }
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+
+	"github.com/handymesh/hyshAuthService/middleware"
+)
+
+var log = logrus.New()
+
+func init() {
+	// Logging =================================================================
+	// Setup the logger backend using Sirupsen/logrus and configure
+	// it to use a custom JSONFormatter. See the logrus docs for how to
+	// configure the backend at github.com/Sirupsen/logrus
+	log.Formatter = new(logrus.JSONFormatter)
+}
+
+// Routes creates a REST router for operators
+func Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Admin)
+
+	r.Get("/keys", ListKeys)
+	r.Post("/keys/rotate", RotateKey)
+	r.Post("/users/{userId}/revoke", RevokeUserTokens)
+
+	r.Get("/clients", ListClients)
+	r.Post("/clients", CreateClient)
+	r.Get("/clients/{clientId}", GetClient)
+	r.Put("/clients/{clientId}", UpdateClient)
+	r.Delete("/clients/{clientId}", DeleteClient)
+	r.Post("/clients/{clientId}/secret", RotateClientSecret)
+	r.Post("/registration-tokens", CreateInitialAccessToken)
+
+	r.Get("/audit", ListAudit)
+
+	r.Get("/outbox", GetOutboxStats)
+	r.Get("/outbox/messages", ListOutboxMessages)
+	r.Post("/outbox/messages/{messageId}/retry", RetryOutboxMessage)
+
+	return r
+}
@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	auditModel "github.com/handymesh/hyshAuthService/models/audit"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+const AUDIT_DEFAULT_LIMIT = 100
+
+// ListAudit returns the newest audit records, optionally of one subject
+func ListAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	limit := int64(AUDIT_DEFAULT_LIMIT)
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || limit <= 0 {
+			utils.Error(w, errors.New(`"limit must be a positive number"`), http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := auditModel.List(r.URL.Query().Get("subject_id"), limit)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, records, http.StatusOK)
+}
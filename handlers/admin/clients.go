@@ -0,0 +1,178 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+// clientOutput carries the client secret, which is only shown once
+type clientOutput struct {
+	*clientModel.Client
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+func ListClients(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	clients, err := clientModel.List()
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, clients, http.StatusOK)
+}
+
+func GetClient(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	client, err := clientModel.FindByID(chi.URLParam(r, "clientId"))
+	if err == clientModel.ErrClientNotFound {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, client, http.StatusOK)
+}
+
+func CreateClient(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	client, err := readClient(r)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	secret, err := clientModel.Add(client)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	log.Info("Client ", client.Id, " registered by operator")
+
+	writeResponse(w, clientOutput{client, secret}, http.StatusCreated)
+}
+
+func UpdateClient(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	client, err := readClient(r)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+	client.Id = chi.URLParam(r, "clientId")
+
+	secret, err := clientModel.Update(client)
+	if err == clientModel.ErrClientNotFound {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	log.Info("Client ", client.Id, " updated by operator")
+
+	writeResponse(w, clientOutput{client, secret}, http.StatusOK)
+}
+
+// DeleteClient removes the client and revokes the tokens it obtained with its credentials
+func DeleteClient(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var clientId = chi.URLParam(r, "clientId")
+	count, err := clientModel.Delete(clientId)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+	if count == 0 {
+		utils.Error(w, errors.New(`"client not found"`), http.StatusNotFound)
+		return
+	}
+
+	err = sessionModel.RevokeUserTokens(clientId)
+	if err != nil {
+		log.Error("Fail revoke client tokens: ", err)
+	}
+
+	log.Info("Client ", clientId, " deleted by operator")
+
+	writeResponse(w, "", http.StatusOK)
+}
+
+// RotateClientSecret issues a new secret, the previous one
+// is accepted until the end of the grace period
+func RotateClientSecret(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var clientId = chi.URLParam(r, "clientId")
+	secret, err := clientModel.RotateSecret(clientId)
+	if err == clientModel.ErrClientNotFound {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	log.Info("Secret of client ", clientId, " rotated by operator")
+
+	writeResponse(w, struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}{clientId, secret}, http.StatusOK)
+}
+
+func readClient(r *http.Request) (*clientModel.Client, error) {
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var client *clientModel.Client
+	err = json.Unmarshal(b, &client)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.New("client is required")
+	}
+
+	return client, nil
+}
+
+func writeResponse(w http.ResponseWriter, data interface{}, status int) {
+	response := utils.ResponseType{
+		Data:    data,
+		Status:  status,
+		Message: "Success",
+	}
+
+	output, err := json.Marshal(response)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(output)
+}
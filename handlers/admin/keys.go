@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+type keyOutput struct {
+	Kid       string     `json:"kid"`
+	Alg       string     `json:"alg"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+func ListKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only the key signing new tokens is active, the others only verify
+	var activeKid string
+	if active := sessionModel.ActiveSigningKey(); active != nil {
+		activeKid = active.Kid
+	}
+
+	keys := []keyOutput{}
+	for _, key := range sessionModel.SigningKeys() {
+		keys = append(keys, keyOutput{
+			Kid:       key.Kid,
+			Alg:       key.Method.Alg(),
+			Active:    key.Kid == activeKid,
+			CreatedAt: key.CreatedAt,
+			RetiredAt: key.RetiredAt,
+		})
+	}
+
+	response := utils.ResponseType{
+		Data:    keys,
+		Status:  http.StatusOK,
+		Message: "Success",
+	}
+
+	output, err := json.Marshal(response)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
+
+func RotateKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	key, err := sessionModel.RotateSigningKey()
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusConflict)
+		return
+	}
+
+	log.Info("Signing key rotated by operator, new kid ", key.Kid)
+
+	response := utils.ResponseType{
+		Data: keyOutput{
+			Kid:       key.Kid,
+			Alg:       key.Method.Alg(),
+			Active:    true,
+			CreatedAt: key.CreatedAt,
+		},
+		Status:  http.StatusCreated,
+		Message: "Success",
+	}
+
+	output, err := json.Marshal(response)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(output)
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	outboxModel "github.com/handymesh/hyshAuthService/models/outbox"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+const OUTBOX_DEFAULT_LIMIT = 100
+
+// GetOutboxStats returns the number of mails by delivery status
+func GetOutboxStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	stats, err := outboxModel.GetStats()
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, stats, http.StatusOK)
+}
+
+// ListOutboxMessages returns the newest mails, optionally of one status
+func ListOutboxMessages(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", outboxModel.STATUS_PENDING, outboxModel.STATUS_SENDING, outboxModel.STATUS_SENT, outboxModel.STATUS_DEAD:
+	default:
+		utils.Error(w, errors.New(`"unknown status"`), http.StatusBadRequest)
+		return
+	}
+
+	limit := int64(OUTBOX_DEFAULT_LIMIT)
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || limit <= 0 {
+			utils.Error(w, errors.New(`"limit must be a positive number"`), http.StatusBadRequest)
+			return
+		}
+	}
+
+	messages, err := outboxModel.List(status, limit)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, messages, http.StatusOK)
+}
+
+// RetryOutboxMessage schedules a dead-lettered mail for delivery again
+func RetryOutboxMessage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := outboxModel.Requeue(chi.URLParam(r, "messageId"))
+	if err == outboxModel.ErrMessageNotFound {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	log.Info("Outbox message ", chi.URLParam(r, "messageId"), " requeued by operator")
+
+	writeResponse(w, "", http.StatusOK)
+}
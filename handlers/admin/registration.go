@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+var (
+	// Get configuration
+	INITIAL_ACCESS_TOKEN_DURATION = utils.Getenv("INITIAL_ACCESS_TOKEN_DURATION", "168h")
+)
+
+type initialAccessTokenInput struct {
+	// Lifetime in seconds, INITIAL_ACCESS_TOKEN_DURATION by default
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+type initialAccessTokenOutput struct {
+	Token     string    `json:"initial_access_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateInitialAccessToken issues a token a partner uses
+// once to register a client at /oauth/register
+func CreateInitialAccessToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ttl, err := time.ParseDuration(INITIAL_ACCESS_TOKEN_DURATION)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusInternalServerError)
+		return
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	var input initialAccessTokenInput
+	if len(b) > 0 {
+		err = json.Unmarshal(b, &input)
+		if err != nil {
+			utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+			return
+		}
+	}
+	if input.ExpiresIn < 0 {
+		utils.Error(w, errors.New(`"expires_in must not be negative"`), http.StatusBadRequest)
+		return
+	}
+	if input.ExpiresIn > 0 {
+		ttl = time.Duration(input.ExpiresIn) * time.Second
+	}
+
+	token, record, err := sessionModel.NewInitialAccessToken(ttl)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	log.Info("Initial access token issued by operator, expires at ", record.ExpiresAt)
+
+	writeResponse(w, initialAccessTokenOutput{token, record.ExpiresAt}, http.StatusCreated)
+}
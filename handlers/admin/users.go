@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+// RevokeUserTokens signs the user out of every session
+func RevokeUserTokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var userId = chi.URLParam(r, "userId")
+	if len(userId) != 24 {
+		utils.Error(w, errors.New("not correct user id"), http.StatusBadRequest)
+		return
+	}
+
+	err := sessionModel.RevokeUserTokens(userId)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	log.Info("Tokens of user ", userId, " revoked by operator")
+
+	writeResponse(w, "", http.StatusOK)
+}
@@ -0,0 +1,150 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/handymesh/hyshAuthService/middleware"
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+var (
+	// Get configuration
+	OAUTH_LOGIN_URL = utils.Getenv("OAUTH_LOGIN_URL", "http://localhost:3000/login")
+)
+
+// Authorize issues an authorization code for the signed-in user (RFC 6749, section 4.1
+// with PKCE, RFC 7636). Browsers without an access token are sent to the login page,
+// which returns to this endpoint; the consent page POSTs the same parameters with
+// the user's access token and receives the redirect URI as JSON.
+func Authorize(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		oauthError(w, "invalid_request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := r.Form
+	client, err := clientModel.FindByID(params.Get("client_id"))
+	if err != nil {
+		oauthError(w, "invalid_client", "unknown client", http.StatusBadRequest)
+		return
+	}
+
+	// Never redirect to an unregistered URI
+	redirectURI := params.Get("redirect_uri")
+	if !client.HasRedirectURI(redirectURI) {
+		oauthError(w, "invalid_request", "redirect_uri is not registered", http.StatusBadRequest)
+		return
+	}
+
+	state := params.Get("state")
+	fail := func(code string, description string) {
+		redirect(w, r, redirectURI, url.Values{
+			"error":             {code},
+			"error_description": {description},
+			"state":             {state},
+		})
+	}
+
+	if params.Get("response_type") != "code" {
+		fail("unsupported_response_type", "only the code response type is supported")
+		return
+	}
+	if !client.AllowsGrant(clientModel.GRANT_AUTHORIZATION_CODE) {
+		fail("unauthorized_client", "client may not use the authorization code grant")
+		return
+	}
+	if params.Get("code_challenge") == "" || params.Get("code_challenge_method") != sessionModel.CODE_CHALLENGE_S256 {
+		fail("invalid_request", "PKCE with the S256 code challenge method is required")
+		return
+	}
+
+	scope := client.AllowedScope(params.Get("scope"))
+	if scope == "" {
+		fail("invalid_scope", "no requested scope is allowed for the client")
+		return
+	}
+
+	// The user signs in on the login page first
+	claims, err := middleware.Authenticate(r)
+	if err == nil && claims.Principal().IsClient() {
+		err = errors.New("user token required")
+	}
+	if err == nil && !claims.Principal().IsFirstPartyUser() {
+		// Only the consent page of a first-party app may approve a client
+		oauthError(w, "access_denied", "first-party user token required", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		if r.Method != http.MethodGet {
+			oauthError(w, "login_required", "user is not signed in", http.StatusUnauthorized)
+			return
+		}
+
+		returnTo := sessionModel.ISSUER + r.URL.Path + "?" + params.Encode()
+		http.Redirect(w, r, OAUTH_LOGIN_URL+"?return_to="+url.QueryEscape(returnTo), http.StatusFound)
+		return
+	}
+
+	code, err := sessionModel.NewAuthorizationCode(sessionModel.AuthorizationCode{
+		ClientID:            client.Id,
+		RedirectURI:         redirectURI,
+		UserID:              claims.Subject,
+		Scope:               scope,
+		Nonce:               params.Get("nonce"),
+		CodeChallenge:       params.Get("code_challenge"),
+		CodeChallengeMethod: params.Get("code_challenge_method"),
+		AuthTime:            authTime(claims.Principal()),
+	})
+	if err != nil {
+		fail("server_error", "failed to issue authorization code")
+		return
+	}
+
+	redirect(w, r, redirectURI, url.Values{
+		"code":  {code},
+		"state": {state},
+	})
+}
+
+// authTime returns when the user signed in to the session of the token
+func authTime(principal *sessionModel.Principal) time.Time {
+	if session, err := sessionModel.GetSession(principal.SessionID); err == nil {
+		return session.AuthTime
+	}
+	return principal.IssuedAt
+}
+
+// redirect sends the user agent back to the client, or returns
+// the location as JSON to the consent page
+func redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	if params.Get("state") == "" {
+		params.Del("state")
+	}
+
+	location, err := url.Parse(redirectURI)
+	if err != nil {
+		oauthError(w, "invalid_request", "redirect_uri is invalid", http.StatusBadRequest)
+		return
+	}
+
+	query := location.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	location.RawQuery = query.Encode()
+
+	if r.Method == http.MethodGet {
+		http.Redirect(w, r, location.String(), http.StatusFound)
+		return
+	}
+
+	writeJSON(w, struct {
+		RedirectURI string `json:"redirect_uri"`
+	}{location.String()}, http.StatusOK)
+}
@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+// authenticateClient checks the client credentials sent with HTTP Basic
+// (client_secret_basic), in the form body (client_secret_post)
+// or as a signed client assertion (private_key_jwt)
+func authenticateClient(r *http.Request) (*clientModel.Client, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.PostForm.Get("client_assertion_type") != "" {
+		return authenticateAssertion(r)
+	}
+
+	clientId, secret, ok := r.BasicAuth()
+	if ok {
+		// Credentials are form-urlencoded before Basic encoding (RFC 6749, section 2.3.1)
+		clientId, err = url.QueryUnescape(clientId)
+		if err != nil {
+			return nil, clientModel.ErrInvalidClient
+		}
+		secret, err = url.QueryUnescape(secret)
+		if err != nil {
+			return nil, clientModel.ErrInvalidClient
+		}
+	} else {
+		clientId = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	return clientModel.Authenticate(clientId, secret)
+}
+
+// identifyClient authenticates a confidential client,
+// a public client is identified by its client_id alone
+func identifyClient(r *http.Request) (*clientModel.Client, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, hasBasic := r.BasicAuth()
+	if hasBasic || r.PostForm.Get("client_secret") != "" || r.PostForm.Get("client_assertion_type") != "" {
+		return authenticateClient(r)
+	}
+
+	client, err := clientModel.FindByID(r.PostForm.Get("client_id"))
+	if err != nil || client.IsConfidential() {
+		return nil, clientModel.ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// authenticateAssertion checks a private_key_jwt assertion (RFC 7523, section 2.2),
+// addressed to the issuer or to the endpoint it is sent to
+func authenticateAssertion(r *http.Request) (*clientModel.Client, error) {
+	if r.PostForm.Get("client_assertion_type") != clientModel.CLIENT_ASSERTION_TYPE_JWT {
+		return nil, clientModel.ErrInvalidClient
+	}
+
+	client, err := clientModel.AuthenticateAssertion(
+		r.PostForm.Get("client_assertion"),
+		sessionModel.ISSUER,
+		sessionModel.ISSUER+r.URL.Path,
+	)
+	if err != nil {
+		return nil, clientModel.ErrInvalidClient
+	}
+
+	// A client_id sent along must match the assertion
+	if clientId := r.PostForm.Get("client_id"); clientId != "" && clientId != client.Id {
+		return nil, clientModel.ErrInvalidClient
+	}
+
+	return client, nil
+}
@@ -0,0 +1,216 @@
+package oauth
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/handymesh/hyshAuthService/handlers/session"
+	"github.com/handymesh/hyshAuthService/middleware"
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	userModel "github.com/handymesh/hyshAuthService/models/user"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+var (
+	// Get configuration
+	OAUTH_DEVICE_VERIFICATION_URL = utils.Getenv("OAUTH_DEVICE_VERIFICATION_URL", "http://localhost:3000/device")
+)
+
+// DeviceAuthorizationResponse is the response of RFC 8628, section 3.2
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceVerification describes a pending request to the signed-in user before approval
+type DeviceVerification struct {
+	UserCode   string `json:"user_code"`
+	ClientID   string `json:"client_id"`
+	ClientName string `json:"client_name"`
+	Scope      string `json:"scope"`
+	Status     string `json:"status,omitempty"`
+}
+
+// DeviceAuthorization starts the device flow for a device without a browser (RFC 8628)
+func DeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	client, err := identifyClient(r)
+	if err != nil {
+		oauthError(w, "invalid_client", "client authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	if !client.AllowsGrant(clientModel.GRANT_DEVICE_CODE) {
+		oauthError(w, "unauthorized_client", "client may not use the device authorization grant", http.StatusBadRequest)
+		return
+	}
+
+	scope := client.AllowedScope(r.PostForm.Get("scope"))
+	if scope == "" {
+		oauthError(w, "invalid_scope", "no requested scope is allowed for the client", http.StatusBadRequest)
+		return
+	}
+
+	deviceCode, request, err := sessionModel.NewDeviceAuthorization(client.Id, scope)
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                request.UserCode,
+		VerificationURI:         OAUTH_DEVICE_VERIFICATION_URL,
+		VerificationURIComplete: OAUTH_DEVICE_VERIFICATION_URL + "?user_code=" + request.UserCode,
+		ExpiresIn:               int64(sessionModel.DEVICE_CODE_DURATION.Seconds()),
+		Interval:                int64(request.Interval.Seconds()),
+	}, http.StatusOK)
+}
+
+// GetDeviceVerification shows the signed-in user which client asks for which scope
+func GetDeviceVerification(w http.ResponseWriter, r *http.Request) {
+	if !countUserCodeEntry(w, r) {
+		return
+	}
+
+	_, request, err := sessionModel.FindDeviceAuthorization(r.URL.Query().Get("user_code"))
+	if err != nil {
+		oauthError(w, "invalid_request", "user code is invalid or expired", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, newDeviceVerification(request), http.StatusOK)
+}
+
+// VerifyDevice approves or denies the request of the user code for the signed-in user.
+// The action form parameter is "approve" or "deny".
+func VerifyDevice(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		oauthError(w, "invalid_request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	action := r.PostForm.Get("action")
+	if action != "approve" && action != "deny" {
+		oauthError(w, "invalid_request", "action must be approve or deny", http.StatusBadRequest)
+		return
+	}
+
+	if !countUserCodeEntry(w, r) {
+		return
+	}
+
+	principal := middleware.GetPrincipal(r.Context())
+	request, err := sessionModel.CompleteDeviceAuthorization(
+		r.PostForm.Get("user_code"),
+		principal.Subject,
+		authTime(principal),
+		action == "approve",
+	)
+	if err == sessionModel.ErrUserCodeInvalid {
+		oauthError(w, "invalid_request", "user code is invalid or expired", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	verification := newDeviceVerification(request)
+	verification.Status = request.Status
+	writeJSON(w, verification, http.StatusOK)
+}
+
+// deviceCodeGrant answers the polling device (RFC 8628, section 3.5)
+func deviceCodeGrant(w http.ResponseWriter, r *http.Request, client *clientModel.Client) {
+	request, err := sessionModel.PollDeviceAuthorization(r.PostForm.Get("device_code"), client.Id)
+	switch err {
+	case nil:
+	case sessionModel.ErrDeviceSlowDown:
+		oauthError(w, "slow_down", "", http.StatusBadRequest)
+		return
+	case sessionModel.ErrDeviceCodeExpired:
+		oauthError(w, "expired_token", "", http.StatusBadRequest)
+		return
+	case sessionModel.ErrDeviceCodeInvalid:
+		oauthError(w, "invalid_grant", "device code is invalid", http.StatusBadRequest)
+		return
+	default:
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	switch request.Status {
+	case sessionModel.DEVICE_STATUS_PENDING:
+		oauthError(w, "authorization_pending", "", http.StatusBadRequest)
+		return
+	case sessionModel.DEVICE_STATUS_DENIED:
+		oauthError(w, "access_denied", "", http.StatusBadRequest)
+		return
+	}
+
+	user, err := userModel.FindByID(request.UserID)
+	if err != nil {
+		oauthError(w, "invalid_grant", "user not found", http.StatusBadRequest)
+		return
+	}
+
+	grant := session.NewGrant(r)
+	grant.ClientID = client.Id
+	grant.Scope = request.Scope
+	grant.AuthTime = request.AuthTime
+
+	tokens, err := session.CreateJWTToken(user, grant)
+	if err == session.ErrUserSuspended {
+		oauthError(w, "invalid_grant", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// countUserCodeEntry limits the user codes a signed-in user can try,
+// per session and per IP, against guessing the code of another device
+func countUserCodeEntry(w http.ResponseWriter, r *http.Request) bool {
+	principal := middleware.GetPrincipal(r.Context())
+	sessionId := principal.SessionID
+	if sessionId == "" {
+		sessionId = principal.Subject
+	}
+
+	err := sessionModel.CountUserCodeEntry(sessionId, session.NewGrant(r).IP)
+	if err == sessionModel.ErrUserCodeEntries {
+		w.Header().Set("Retry-After", strconv.Itoa(int(sessionModel.USER_CODE_ENTRY_WINDOW.Seconds())))
+		oauthError(w, "invalid_request", err.Error(), http.StatusTooManyRequests)
+		return false
+	}
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return false
+	}
+
+	return true
+}
+
+func newDeviceVerification(request *sessionModel.DeviceAuthorization) DeviceVerification {
+	verification := DeviceVerification{
+		UserCode: request.UserCode,
+		ClientID: request.ClientID,
+		Scope:    request.Scope,
+	}
+
+	if client, err := clientModel.FindByID(request.ClientID); err == nil {
+		verification.ClientName = client.Name
+	}
+
+	return verification
+}
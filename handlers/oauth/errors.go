@@ -0,0 +1,45 @@
+package oauth
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// OAuthError is the error response of the OAuth 2.0 endpoints (RFC 6749, section 5.2)
+type OAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func oauthError(w http.ResponseWriter, code string, description string, statusCode int) {
+	// Bearer protected endpoints set their own challenge
+	if statusCode == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="hysh"`)
+	}
+
+	writeJSON(w, OAuthError{Error: code, ErrorDescription: description}, statusCode)
+}
+
+// bearerError answers a request with a missing or bad bearer token (RFC 6750, section 3)
+func bearerError(w http.ResponseWriter, code string, description string, statusCode int) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="`+code+`"`)
+	oauthError(w, code, description, statusCode)
+}
+
+// writeJSON writes a bare JSON document, as the OAuth 2.0 endpoints
+// respond without the utils.ResponseType envelope
+func writeJSON(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	output, err := json.Marshal(data)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"server_error"}`))
+		return
+	}
+
+	w.WriteHeader(statusCode)
+	w.Write(output)
+}
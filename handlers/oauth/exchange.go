@@ -0,0 +1,227 @@
+package oauth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/handymesh/hyshAuthService/handlers/session"
+	auditModel "github.com/handymesh/hyshAuthService/models/audit"
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	userModel "github.com/handymesh/hyshAuthService/models/user"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+const TOKEN_TYPE_ACCESS_TOKEN = "urn:ietf:params:oauth:token-type:access_token"
+
+var (
+	// Get configuration
+	TOKEN_EXCHANGE_IMPERSONATION_ROLES = utils.Getenv("TOKEN_EXCHANGE_IMPERSONATION_ROLES", "admin support")
+)
+
+// tokenExchangeGrant exchanges an access token for another one (RFC 8693).
+// Without requested_subject the new token keeps the subject of the subject token,
+// with a narrower scope and no roles, and names the caller in the act claim
+// (delegation). The subject token must have been issued to the calling client.
+// With requested_subject a staff member with an impersonation role obtains
+// a token for that user, with the staff member in the act claim. The staff
+// token must have been issued to the calling client, or the caller is a
+// first-party client.
+// Every exchange is recorded in the audit log.
+func tokenExchangeGrant(w http.ResponseWriter, r *http.Request, client *clientModel.Client) {
+	if !client.IsConfidential() {
+		oauthError(w, "unauthorized_client", "public clients may not exchange tokens", http.StatusBadRequest)
+		return
+	}
+
+	form := r.PostForm
+	if form.Get("subject_token_type") != TOKEN_TYPE_ACCESS_TOKEN {
+		oauthError(w, "invalid_request", "subject_token_type must be "+TOKEN_TYPE_ACCESS_TOKEN, http.StatusBadRequest)
+		return
+	}
+	if t := form.Get("requested_token_type"); t != "" && t != TOKEN_TYPE_ACCESS_TOKEN {
+		oauthError(w, "invalid_request", "only access tokens can be requested", http.StatusBadRequest)
+		return
+	}
+	for _, target := range append(form["audience"], form["resource"]...) {
+		if target != sessionModel.AUDIENCE && target != sessionModel.ISSUER {
+			oauthError(w, "invalid_target", "unknown audience "+target, http.StatusBadRequest)
+			return
+		}
+	}
+
+	subject, err := sessionModel.ParseAccessToken(form.Get("subject_token"))
+	if err != nil {
+		oauthError(w, "invalid_grant", "subject token is invalid", http.StatusBadRequest)
+		return
+	}
+
+	var claims *sessionModel.Claims
+	var denied *OAuthError
+	var recordType string
+	if form.Get("requested_subject") != "" {
+		claims, denied = impersonationClaims(subject, form.Get("requested_subject"), client)
+		recordType = auditModel.TYPE_IMPERSONATION
+	} else {
+		claims, denied = delegationClaims(subject, form.Get("actor_token"), form.Get("actor_token_type"), client)
+		recordType = auditModel.TYPE_TOKEN_EXCHANGE
+	}
+	if denied != nil {
+		oauthError(w, denied.Error, denied.ErrorDescription, http.StatusBadRequest)
+		return
+	}
+
+	scope, ok := narrowScope(claims.Scope, form.Get("scope"), client)
+	if !ok {
+		oauthError(w, "invalid_scope", "requested scope exceeds the subject token", http.StatusBadRequest)
+		return
+	}
+	claims.Scope = scope
+	claims.ClientID = client.Id
+
+	// The new token does not outlive the subject token
+	claims.ExpiresAt = subject.ExpiresAt
+	bindToken(r, claims)
+
+	access, err := sessionModel.NewAccessToken(claims)
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	// An exchange that can not be audited is refused
+	err = auditModel.Add(&auditModel.Record{
+		Type:      recordType,
+		ActorID:   claims.Act.Subject,
+		SubjectID: claims.Subject,
+		ClientID:  client.Id,
+		Scope:     claims.Scope,
+		TokenID:   claims.Id,
+		IP:        session.NewGrant(r).IP,
+	})
+	if err != nil {
+		log.Error("Fail record token exchange: ", err)
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, TokenResponse{
+		AccessToken:     access,
+		TokenType:       session.TokenType(claims),
+		IssuedTokenType: TOKEN_TYPE_ACCESS_TOKEN,
+		ExpiresIn:       int64(time.Until(time.Unix(claims.ExpiresAt, 0)).Seconds()),
+		Scope:           claims.Scope,
+	}, http.StatusOK)
+}
+
+// delegationClaims keeps the subject and adds the actor token's subject,
+// or the client itself, as the current actor. The delegate acts with
+// the scope of the subject token, the roles of the subject stay with it.
+func delegationClaims(subject *sessionModel.Claims, actorToken string, actorTokenType string, client *clientModel.Client) (*sessionModel.Claims, *OAuthError) {
+	actor := &sessionModel.Actor{Subject: client.Id, SubjectType: sessionModel.SUBJECT_TYPE_CLIENT}
+	if actorToken != "" {
+		if actorTokenType != TOKEN_TYPE_ACCESS_TOKEN {
+			return nil, &OAuthError{Error: "invalid_request", ErrorDescription: "actor_token_type must be " + TOKEN_TYPE_ACCESS_TOKEN}
+		}
+
+		actorClaims, err := sessionModel.ParseAccessToken(actorToken)
+		if err != nil {
+			return nil, &OAuthError{Error: "invalid_grant", ErrorDescription: "actor token is invalid"}
+		}
+
+		principal := actorClaims.Principal()
+		actor = &sessionModel.Actor{Subject: principal.Subject, SubjectType: principal.SubjectType}
+	}
+
+	if subject.ClientID != client.Id {
+		return nil, &OAuthError{Error: "invalid_grant", ErrorDescription: "subject token was not issued to the client"}
+	}
+	actor.Act = subject.Act
+
+	return &sessionModel.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: subject.Subject},
+		SubjectType:    subject.SubjectType,
+		SessionID:      subject.SessionID,
+		Scope:          subject.Scope,
+		Act:            actor,
+	}, nil
+}
+
+// impersonationClaims issues a token for the requested user to a staff member.
+// Only admins may impersonate admins, and impersonation tokens can not be exchanged again.
+func impersonationClaims(subject *sessionModel.Claims, requestedSubject string, client *clientModel.Client) (*sessionModel.Claims, *OAuthError) {
+	// A staff token leaked to another client must not be usable for impersonation
+	if subject.ClientID != client.Id && !sessionModel.IsFirstParty(client.Id) {
+		return nil, &OAuthError{Error: "invalid_grant", ErrorDescription: "subject token was not issued to the client"}
+	}
+
+	staff := subject.Principal()
+	if staff.IsClient() || staff.Actor != nil || !hasAnyRole(staff, strings.Fields(TOKEN_EXCHANGE_IMPERSONATION_ROLES)) {
+		return nil, &OAuthError{Error: "invalid_grant", ErrorDescription: "subject may not impersonate users"}
+	}
+
+	user, err := userModel.FindByID(requestedSubject)
+	if err != nil || !user.IsActive() {
+		return nil, &OAuthError{Error: "invalid_request", ErrorDescription: "requested subject not found"}
+	}
+
+	for _, role := range user.GetRoles() {
+		if role == userModel.ROLE_ADMIN && !staff.HasRole(userModel.ROLE_ADMIN) {
+			return nil, &OAuthError{Error: "invalid_grant", ErrorDescription: "subject may not impersonate admins"}
+		}
+	}
+
+	// The staff session bounds the impersonation, signing out ends it
+	return &sessionModel.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: user.Id},
+		SubjectType:    sessionModel.SUBJECT_TYPE_USER,
+		SessionID:      staff.SessionID,
+		Roles:          user.GetRoles(),
+		Scope:          staff.Scope(),
+		Act:            &sessionModel.Actor{Subject: staff.Subject, SubjectType: sessionModel.SUBJECT_TYPE_USER},
+	}, nil
+}
+
+// narrowScope returns the requested scope if the current scope and the client allow all of it.
+// An empty request keeps the current scope within the scopes of the client.
+func narrowScope(current string, requested string, client *clientModel.Client) (string, bool) {
+	// AllowedScope grants every client scope for an empty scope
+	if strings.TrimSpace(current) == "" {
+		return "", requested == ""
+	}
+
+	allowed := client.AllowedScope(current)
+	if requested == "" {
+		return allowed, true
+	}
+
+	granted := strings.Fields(allowed)
+	for _, scope := range strings.Fields(requested) {
+		if !hasScope(granted, scope) {
+			return "", false
+		}
+	}
+
+	return strings.Join(strings.Fields(requested), " "), true
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyRole(principal *sessionModel.Principal, roles []string) bool {
+	for _, role := range roles {
+		if principal.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+func TestDelegationClaims(t *testing.T) {
+	client := &clientModel.Client{Id: "partner"}
+	subjectOf := func(clientId string) *sessionModel.Claims {
+		return &sessionModel.Claims{
+			StandardClaims: jwt.StandardClaims{Subject: "5c3a1b2e9d4f6a7b8c9d0e1f"},
+			ClientID:       clientId,
+			Roles:          []string{"admin"},
+			Scope:          "openid profile",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		subject *sessionModel.Claims
+		err     string
+	}{
+		{"token of the client", subjectOf("partner"), ""},
+		{"token of another client", subjectOf("other"), "invalid_grant"},
+		{"token of a first-party client", subjectOf(sessionModel.FIRST_PARTY_CLIENT_IDS[0]), "invalid_grant"},
+	}
+
+	for _, test := range tests {
+		claims, denied := delegationClaims(test.subject, "", "", client)
+		if denied != nil {
+			if denied.Error != test.err {
+				t.Errorf("[%s] got %v want %v", test.name, denied.Error, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("[%s] got claims want %v", test.name, test.err)
+			continue
+		}
+		if claims.Roles != nil || claims.Act == nil || claims.Act.Subject != client.Id {
+			t.Errorf("[%s] got roles %v and actor %+v", test.name, claims.Roles, claims.Act)
+		}
+	}
+}
+
+func TestImpersonationClaimsRequiresClientOfStaffToken(t *testing.T) {
+	// Roles are only honoured in tokens of first-party clients
+	staff := &sessionModel.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: "5c3a1b2e9d4f6a7b8c9d0e1f"},
+		ClientID:       sessionModel.FIRST_PARTY_CLIENT_IDS[0],
+		Roles:          []string{"support"},
+	}
+
+	_, denied := impersonationClaims(staff, "5c3a1b2e9d4f6a7b8c9d0e20", &clientModel.Client{Id: "partner"})
+	if denied == nil || denied.Error != "invalid_grant" {
+		t.Errorf("staff token of another client: got %v want invalid_grant", denied)
+	}
+}
@@ -84,6 +84,10 @@ func googleCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + token.AccessToken)
+	if err != nil {
+		utils.Error(w, errors.New("\"cannot fetch user info\""), http.StatusBadRequest)
+		return
+	}
 	defer response.Body.Close()
 
 	contents, err := ioutil.ReadAll(response.Body)
@@ -95,26 +99,41 @@ func googleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	grant, err := session.FirstPartyGrant(r)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusUnauthorized)
+		return
+	}
+
 	var searchUser = userModel.User{Email: &userGoogle.Email}
-	_, err = userModel.FindOne(searchUser)
+	user, err := userModel.FindOne(searchUser)
 	if err != nil {
 		searchUser.Gender = userGoogle.Gender
-		userModel.Add(searchUser)
+		searchUser.Fullname = userGoogle.Name
+		searchUser.Locale = userGoogle.Locale
+		searchUser.EmailVerified = userGoogle.VerifiedEmail
+		err, searchUser = userModel.Add(searchUser)
+		if err != nil {
+			utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+			return
+		}
+		user = &searchUser
 	}
 
 	// Create JWT token
-	tokenString, refreshToken, err := session.CreateJWTToken()
+	tokens, err := session.CreateJWTToken(user, grant)
 	if err != nil {
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Authorization", tokenString)
+	w.Header().Set("Authorization", tokens.Access)
 	w.WriteHeader(http.StatusCreated)
 	w.Write([]byte(`{
 		"tokens": {
-			"access": "` + tokenString + `",
-			"refresh": "` + refreshToken + `"
+			"access": "` + tokens.Access + `",
+			"refresh": "` + tokens.Refresh + `",
+			"id": "` + tokens.ID + `"
 		}
 	}`))
 }
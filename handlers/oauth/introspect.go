@@ -0,0 +1,100 @@
+package oauth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/handymesh/hyshAuthService/handlers/session"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+// IntrospectionResponse is the token metadata of RFC 7662, section 2.2
+type IntrospectionResponse struct {
+	Active    bool                       `json:"active"`
+	Scope     string                     `json:"scope,omitempty"`
+	ClientID  string                     `json:"client_id,omitempty"`
+	TokenType string                     `json:"token_type,omitempty"`
+	Exp       int64                      `json:"exp,omitempty"`
+	Iat       int64                      `json:"iat,omitempty"`
+	Sub       string                     `json:"sub,omitempty"`
+	SubType   string                     `json:"sub_type,omitempty"`
+	Aud       string                     `json:"aud,omitempty"`
+	Iss       string                     `json:"iss,omitempty"`
+	Jti       string                     `json:"jti,omitempty"`
+	Sid       string                     `json:"sid,omitempty"`
+	Act       *sessionModel.Actor        `json:"act,omitempty"`
+	Cnf       *sessionModel.Confirmation `json:"cnf,omitempty"`
+}
+
+// Introspect tells an authenticated client whether a token is active (RFC 7662)
+func Introspect(w http.ResponseWriter, r *http.Request) {
+	_, err := authenticateClient(r)
+	if err != nil {
+		oauthError(w, "invalid_client", "client authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	token := r.PostForm.Get("token")
+	if token == "" {
+		oauthError(w, "invalid_request", "token is required", http.StatusBadRequest)
+		return
+	}
+
+	// Try the hinted token type first, an unknown hint is ignored
+	inspectors := []func(string) *IntrospectionResponse{introspectAccessToken, introspectRefreshToken}
+	if r.PostForm.Get("token_type_hint") == "refresh_token" || !strings.Contains(token, ".") {
+		inspectors = []func(string) *IntrospectionResponse{introspectRefreshToken, introspectAccessToken}
+	}
+
+	for _, inspect := range inspectors {
+		if response := inspect(token); response != nil {
+			writeJSON(w, response, http.StatusOK)
+			return
+		}
+	}
+
+	writeJSON(w, IntrospectionResponse{Active: false}, http.StatusOK)
+}
+
+func introspectAccessToken(token string) *IntrospectionResponse {
+	claims, err := sessionModel.ParseAccessToken(token)
+	if err != nil {
+		return nil
+	}
+
+	return &IntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		TokenType: session.TokenType(claims),
+		Exp:       claims.ExpiresAt,
+		Iat:       claims.IssuedAt,
+		Sub:       claims.Subject,
+		SubType:   claims.Principal().SubjectType,
+		Aud:       claims.Audience,
+		Iss:       claims.Issuer,
+		Jti:       claims.Id,
+		Sid:       claims.SessionID,
+		Act:       claims.Act,
+		Cnf:       claims.Cnf,
+	}
+}
+
+func introspectRefreshToken(token string) *IntrospectionResponse {
+	record, session, err := sessionModel.InspectRefreshToken(token)
+	if err != nil {
+		return nil
+	}
+
+	return &IntrospectionResponse{
+		Active:    true,
+		ClientID:  record.ClientID,
+		TokenType: "refresh_token",
+		Exp:       session.Expiry().Unix(),
+		Iat:       record.IssuedAt.Unix(),
+		Sub:       record.UserID,
+		SubType:   sessionModel.SUBJECT_TYPE_USER,
+		Iss:       sessionModel.ISSUER,
+		Sid:       session.ID,
+	}
+}
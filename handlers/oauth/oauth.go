@@ -19,10 +19,39 @@ func init() {
 // Routes creates a REST router
 func Routes() chi.Router {
 	r := chi.NewRouter()
-	r.Use(middleware.Captcha)
 
-	r.Get("/google", googleOAuth)
-	r.Post("/callback/google", googleCallback)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Captcha)
+
+		r.Get("/google", googleOAuth)
+		r.Post("/callback/google", googleCallback)
+	})
+
+	// OAuth 2.0 endpoints take form-encoded requests
+	r.Get("/authorize", Authorize)
+	r.Post("/authorize", Authorize)
+	r.With(middleware.DPoP).Post("/token", Token)
+	r.Post("/device_authorization", DeviceAuthorization)
+	r.Post("/introspect", Introspect)
+	r.Post("/revoke", Revoke)
+
+	// Dynamic client registration takes JSON requests
+	r.Post("/register", Register)
+	r.Get("/register/{clientId}", GetRegistration)
+	r.Put("/register/{clientId}", UpdateRegistration)
+	r.Delete("/register/{clientId}", DeleteRegistration)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.CheckAuth)
+		r.Use(middleware.RequireUser)
+
+		r.Get("/userinfo", UserInfo)
+		r.Post("/userinfo", UserInfo)
+
+		// Only the first-party apps may approve a device for the user
+		r.With(middleware.RequireFirstParty).Get("/device", GetDeviceVerification)
+		r.With(middleware.RequireFirstParty).Post("/device", VerifyDevice)
+	})
 
 	return r
 }
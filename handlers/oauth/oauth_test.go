@@ -0,0 +1,138 @@
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dgrijalva/jwt-go"
+	goredis "github.com/go-redis/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/handymesh/hyshAuthService/db/mongodb"
+	"github.com/handymesh/hyshAuthService/db/redis"
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+// newTestToken signs an access token of the claims with a key
+// the validator accepts for the duration of the test
+func newTestToken(t *testing.T, claims sessionModel.Claims) string {
+	key, err := sessionModel.NewSigningKey("ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFunc := sessionModel.AccessTokens.Key
+	sessionModel.AccessTokens.Key = func(kid string) *sessionModel.SigningKey {
+		if kid == key.Kid {
+			return key
+		}
+		return keyFunc(kid)
+	}
+	t.Cleanup(func() { sessionModel.AccessTokens.Key = keyFunc })
+
+	now := time.Now()
+	claims.Id = now.Format(time.RFC3339Nano)
+	claims.Issuer = sessionModel.ISSUER
+	claims.Audience = sessionModel.AUDIENCE
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(time.Minute).Unix()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.Kid
+	signed, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func newTestRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	redis.Redis = goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { redis.Redis.Close() })
+}
+
+func TestThirdPartyUserToken(t *testing.T) {
+	newTestRedis(t)
+
+	userClaims := func(clientId string) sessionModel.Claims {
+		return sessionModel.Claims{
+			StandardClaims: jwt.StandardClaims{Subject: "5c3a1b2e9d4f6a7b8c9d0e1f"},
+			ClientID:       clientId,
+			Roles:          []string{"admin"},
+		}
+	}
+	firstParty := newTestToken(t, userClaims(sessionModel.FIRST_PARTY_CLIENT_IDS[0]))
+	thirdParty := newTestToken(t, userClaims("third-party"))
+	service := newTestToken(t, sessionModel.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: "service"},
+		SubjectType:    sessionModel.SUBJECT_TYPE_CLIENT,
+		ClientID:       "service",
+	})
+
+	device := url.Values{"user_code": {"ABCD-EFGH"}, "action": {"approve"}}.Encode()
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"first-party user", firstParty, http.StatusBadRequest},
+		{"third-party user", thirdParty, http.StatusForbidden},
+		{"service", service, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/device", strings.NewReader(device))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+test.token)
+
+		w := httptest.NewRecorder()
+		Routes().ServeHTTP(w, req)
+		if w.Code != test.status {
+			t.Errorf("[%s /oauth/device] got %v want %v", test.name, w.Code, test.status)
+		}
+	}
+
+	// The consent of a third-party client must not mint a code for another client
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("authorize", func(mt *mtest.T) {
+		session := mongodb.Session
+		mongodb.Session = mt.Client
+		defer func() { mongodb.Session = session }()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "auth.clients", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "hysh-mobile"},
+			{Key: "redirect_uris", Value: bson.A{"https://app.hysh.test/callback"}},
+			{Key: "grant_types", Value: bson.A{clientModel.GRANT_AUTHORIZATION_CODE}},
+			{Key: "scopes", Value: bson.A{"openid"}},
+		}))
+
+		authorize := url.Values{
+			"client_id":             {"hysh-mobile"},
+			"redirect_uri":          {"https://app.hysh.test/callback"},
+			"response_type":         {"code"},
+			"code_challenge":        {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+			"code_challenge_method": {sessionModel.CODE_CHALLENGE_S256},
+		}.Encode()
+		req := httptest.NewRequest(http.MethodPost, "/authorize", strings.NewReader(authorize))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+thirdParty)
+
+		w := httptest.NewRecorder()
+		Routes().ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("[third-party user /oauth/authorize] got %v want %v", w.Code, http.StatusForbidden)
+		}
+	})
+}
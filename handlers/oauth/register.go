@@ -0,0 +1,260 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+
+	"github.com/handymesh/hyshAuthService/middleware"
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+	"github.com/handymesh/hyshAuthService/utils/jose"
+)
+
+var (
+	// Get configuration
+	REGISTRATION_ALLOWED_SCOPES = utils.Getenv("REGISTRATION_ALLOWED_SCOPES", "openid profile email")
+
+	// Grants a self-registered client may use, client_credentials and
+	// token exchange are for clients created by an operator
+	registrationGrantTypes = []string{
+		clientModel.GRANT_AUTHORIZATION_CODE,
+		clientModel.GRANT_REFRESH_TOKEN,
+		clientModel.GRANT_DEVICE_CODE,
+	}
+)
+
+// ClientMetadata is the client metadata of RFC 7591, section 2
+type ClientMetadata struct {
+	RedirectURIs            []string   `json:"redirect_uris,omitempty"`
+	TokenEndpointAuthMethod string     `json:"token_endpoint_auth_method,omitempty"`
+	GrantTypes              []string   `json:"grant_types,omitempty"`
+	ResponseTypes           []string   `json:"response_types,omitempty"`
+	ClientName              string     `json:"client_name,omitempty"`
+	Scope                   string     `json:"scope,omitempty"`
+	JWKS                    *jose.JWKS `json:"jwks,omitempty"`
+}
+
+// ClientInformation is the registration response of RFC 7591, section 3.2.1
+type ClientInformation struct {
+	ClientMetadata
+	ClientID                string `json:"client_id"`
+	ClientSecret            string `json:"client_secret,omitempty"`
+	ClientIDIssuedAt        int64  `json:"client_id_issued_at,omitempty"`
+	ClientSecretExpiresAt   *int64 `json:"client_secret_expires_at,omitempty"`
+	RegistrationAccessToken string `json:"registration_access_token,omitempty"`
+	RegistrationClientURI   string `json:"registration_client_uri"`
+}
+
+// Register creates a client for the holder of an initial access token (RFC 7591)
+func Register(w http.ResponseWriter, r *http.Request) {
+	client, ok := readClientMetadata(w, r)
+	if !ok {
+		return
+	}
+
+	// The initial access token is single use, so reject invalid metadata before spending it
+	if client.TokenEndpointAuthMethod == "" {
+		client.TokenEndpointAuthMethod = clientModel.AUTH_METHOD_SECRET_BASIC
+	}
+	err := client.Validate()
+	if err != nil {
+		registrationError(w, err)
+		return
+	}
+
+	_, err = sessionModel.ConsumeInitialAccessToken(middleware.BearerToken(r))
+	if err != nil {
+		bearerError(w, "invalid_token", "initial access token is invalid or expired", http.StatusUnauthorized)
+		return
+	}
+
+	secret, err := clientModel.Add(client)
+	if err != nil {
+		registrationError(w, err)
+		return
+	}
+
+	registrationToken, err := clientModel.NewRegistrationToken(client.Id)
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("Client ", client.Id, " registered dynamically")
+
+	writeJSON(w, newClientInformation(client, secret, registrationToken), http.StatusCreated)
+}
+
+// GetRegistration reads the client configuration (RFC 7592, section 2.1)
+func GetRegistration(w http.ResponseWriter, r *http.Request) {
+	client, ok := registeredClient(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, newClientInformation(client, "", ""), http.StatusOK)
+}
+
+// UpdateRegistration replaces the client configuration (RFC 7592, section 2.2)
+func UpdateRegistration(w http.ResponseWriter, r *http.Request) {
+	current, ok := registeredClient(w, r)
+	if !ok {
+		return
+	}
+
+	client, ok := readClientMetadata(w, r)
+	if !ok {
+		return
+	}
+	client.Id = current.Id
+
+	secret, err := clientModel.Update(client)
+	if err != nil {
+		registrationError(w, err)
+		return
+	}
+
+	writeJSON(w, newClientInformation(client, secret, ""), http.StatusOK)
+}
+
+// DeleteRegistration deprovisions the client and revokes
+// the tokens it obtained with its credentials (RFC 7592, section 2.3)
+func DeleteRegistration(w http.ResponseWriter, r *http.Request) {
+	client, ok := registeredClient(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := clientModel.Delete(client.Id)
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	err = sessionModel.RevokeUserTokens(client.Id)
+	if err != nil {
+		log.Error("Fail revoke client tokens: ", err)
+	}
+
+	log.Info("Client ", client.Id, " deleted by itself")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// registeredClient authenticates the registration access token.
+// Unknown clients get the same answer as wrong tokens.
+func registeredClient(w http.ResponseWriter, r *http.Request) (*clientModel.Client, bool) {
+	client, err := clientModel.AuthenticateRegistration(chi.URLParam(r, "clientId"), middleware.BearerToken(r))
+	if err != nil {
+		bearerError(w, "invalid_token", "registration access token is invalid", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	return client, true
+}
+
+// readClientMetadata decodes the metadata, applies the defaults
+// of RFC 7591 and restricts the redirect URIs and the scope for
+// self-registered clients
+func readClientMetadata(w http.ResponseWriter, r *http.Request) (*clientModel.Client, bool) {
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		oauthError(w, "invalid_client_metadata", err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	var metadata ClientMetadata
+	err = json.Unmarshal(b, &metadata)
+	if err != nil {
+		oauthError(w, "invalid_client_metadata", err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	if len(metadata.GrantTypes) == 0 {
+		metadata.GrantTypes = []string{clientModel.GRANT_AUTHORIZATION_CODE}
+	}
+	registrable := &clientModel.Client{GrantTypes: registrationGrantTypes}
+	for _, grantType := range metadata.GrantTypes {
+		if !registrable.AllowsGrant(grantType) {
+			oauthError(w, "invalid_client_metadata", "unsupported grant type "+grantType, http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	for _, responseType := range metadata.ResponseTypes {
+		if responseType != "code" {
+			oauthError(w, "invalid_client_metadata", "unsupported response type "+responseType, http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	for _, uri := range metadata.RedirectURIs {
+		if !clientModel.RegistrableRedirectURI(uri) {
+			oauthError(w, "invalid_redirect_uri", uri+" must use https, http on a loopback address or a private-use scheme", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	allowed := &clientModel.Client{Scopes: strings.Fields(REGISTRATION_ALLOWED_SCOPES)}
+	scope := allowed.AllowedScope(metadata.Scope)
+	if scope == "" {
+		oauthError(w, "invalid_client_metadata", "no requested scope is allowed", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &clientModel.Client{
+		Name:                    metadata.ClientName,
+		RedirectURIs:            metadata.RedirectURIs,
+		GrantTypes:              metadata.GrantTypes,
+		Scopes:                  strings.Fields(scope),
+		TokenEndpointAuthMethod: metadata.TokenEndpointAuthMethod,
+		JWKS:                    metadata.JWKS,
+	}, true
+}
+
+func newClientInformation(client *clientModel.Client, secret string, registrationToken string) ClientInformation {
+	information := ClientInformation{
+		ClientMetadata: ClientMetadata{
+			RedirectURIs:            client.RedirectURIs,
+			TokenEndpointAuthMethod: client.TokenEndpointAuthMethod,
+			GrantTypes:              client.GrantTypes,
+			ResponseTypes:           []string{},
+			ClientName:              client.Name,
+			Scope:                   strings.Join(client.Scopes, " "),
+			JWKS:                    client.JWKS,
+		},
+		ClientID:                client.Id,
+		ClientSecret:            secret,
+		RegistrationAccessToken: registrationToken,
+		RegistrationClientURI:   sessionModel.ISSUER + "/oauth/register/" + client.Id,
+	}
+
+	if client.AllowsGrant(clientModel.GRANT_AUTHORIZATION_CODE) {
+		information.ResponseTypes = []string{"code"}
+	}
+	if client.CreatedAt != nil {
+		information.ClientIDIssuedAt = client.CreatedAt.Unix()
+	}
+	if secret != "" {
+		// Secrets do not expire
+		var never int64
+		information.ClientSecretExpiresAt = &never
+	}
+
+	return information
+}
+
+func registrationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, clientModel.ErrInvalidRedirectURI) {
+		oauthError(w, "invalid_redirect_uri", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	oauthError(w, "invalid_client_metadata", err.Error(), http.StatusBadRequest)
+}
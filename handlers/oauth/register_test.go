@@ -0,0 +1,49 @@
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+func TestRegisterKeepsTokenOfInvalidMetadata(t *testing.T) {
+	newTestRedis(t)
+
+	token, _, err := sessionModel.NewInitialAccessToken(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		metadata string
+		err      string
+	}{
+		{"malformed", `{"redirect_uris":`, "invalid_client_metadata"},
+		{"without name", `{"redirect_uris":["https://app.hysh.test/callback"],"token_endpoint_auth_method":"none"}`, "invalid_client_metadata"},
+		{"script redirect", `{"client_name":"App","redirect_uris":["javascript:alert(1)"]}`, "invalid_redirect_uri"},
+		{"plain http redirect", `{"client_name":"App","redirect_uris":["http://app.hysh.test/callback"]}`, "invalid_redirect_uri"},
+		{"client credentials", `{"client_name":"App","grant_types":["client_credentials"]}`, "invalid_client_metadata"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(test.metadata))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		Register(w, req)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), test.err) {
+			t.Errorf("[%s] got %v %s want %v %s", test.name, w.Code, w.Body.String(), http.StatusBadRequest, test.err)
+		}
+	}
+
+	// The partner can still register once the metadata is fixed
+	if _, err = sessionModel.ConsumeInitialAccessToken(token); err != nil {
+		t.Errorf("initial access token: got %v", err)
+	}
+}
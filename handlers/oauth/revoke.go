@@ -0,0 +1,79 @@
+package oauth
+
+import (
+	"net/http"
+	"strings"
+
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+// Revoke invalidates a refresh or access token issued to the client (RFC 7009).
+// The response is 200 whether or not the token was known.
+func Revoke(w http.ResponseWriter, r *http.Request) {
+	client, err := identifyClient(r)
+	if err != nil {
+		oauthError(w, "invalid_client", "client authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	token := r.PostForm.Get("token")
+	if token == "" {
+		oauthError(w, "invalid_request", "token is required", http.StatusBadRequest)
+		return
+	}
+
+	// Try the hinted token type first, an unknown hint is ignored
+	revokers := []func(*clientModel.Client, string) bool{revokeAccessToken, revokeRefreshToken}
+	if r.PostForm.Get("token_type_hint") == "refresh_token" || !strings.Contains(token, ".") {
+		revokers = []func(*clientModel.Client, string) bool{revokeRefreshToken, revokeAccessToken}
+	}
+
+	for _, revoke := range revokers {
+		if revoke(client, token) {
+			break
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// revokeRefreshToken ends the session of the refresh token, which
+// also rejects the access tokens issued in that session
+func revokeRefreshToken(client *clientModel.Client, token string) bool {
+	record, session, err := sessionModel.InspectRefreshToken(token)
+	if err != nil {
+		return false
+	}
+
+	// Clients may only revoke their own tokens
+	if record.ClientID != client.Id {
+		return true
+	}
+
+	err = sessionModel.RevokeSession(session.UserID, session.ID)
+	if err != nil {
+		log.Error("Fail revoke session: ", err)
+	}
+
+	return true
+}
+
+func revokeAccessToken(client *clientModel.Client, token string) bool {
+	claims, err := sessionModel.ParseAccessToken(token)
+	if err != nil {
+		return false
+	}
+
+	if claims.ClientID != client.Id {
+		return true
+	}
+
+	err = sessionModel.RevokeAccessToken(claims)
+	if err != nil {
+		log.Error("Fail revoke access token: ", err)
+	}
+
+	return true
+}
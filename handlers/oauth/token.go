@@ -0,0 +1,168 @@
+package oauth
+
+import (
+	"net/http"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/handymesh/hyshAuthService/handlers/session"
+	"github.com/handymesh/hyshAuthService/middleware"
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	userModel "github.com/handymesh/hyshAuthService/models/user"
+)
+
+// TokenResponse is the successful token endpoint response (RFC 6749, section 5.1)
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// Set for token exchange (RFC 8693, section 2.2.1)
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	ExpiresIn       int64  `json:"expires_in"`
+	RefreshToken    string `json:"refresh_token,omitempty"`
+	IDToken         string `json:"id_token,omitempty"`
+	Scope           string `json:"scope,omitempty"`
+}
+
+// grants handled by the token endpoint, by grant_type
+var grants = map[string]func(w http.ResponseWriter, r *http.Request, client *clientModel.Client){
+	clientModel.GRANT_AUTHORIZATION_CODE: authorizationCodeGrant,
+	clientModel.GRANT_REFRESH_TOKEN:      refreshTokenGrant,
+	clientModel.GRANT_CLIENT_CREDENTIALS: clientCredentialsGrant,
+	clientModel.GRANT_DEVICE_CODE:        deviceCodeGrant,
+	clientModel.GRANT_TOKEN_EXCHANGE:     tokenExchangeGrant,
+}
+
+// Token exchanges a grant for tokens
+func Token(w http.ResponseWriter, r *http.Request) {
+	client, err := identifyClient(r)
+	if err != nil {
+		oauthError(w, "invalid_client", "client authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	grantType := r.PostForm.Get("grant_type")
+	grant, ok := grants[grantType]
+	if !ok {
+		oauthError(w, "unsupported_grant_type", "", http.StatusBadRequest)
+		return
+	}
+	if !client.AllowsGrant(grantType) {
+		oauthError(w, "unauthorized_client", "client may not use the "+grantType+" grant", http.StatusBadRequest)
+		return
+	}
+
+	grant(w, r, client)
+}
+
+func authorizationCodeGrant(w http.ResponseWriter, r *http.Request, client *clientModel.Client) {
+	code, err := sessionModel.ConsumeAuthorizationCode(r.PostForm.Get("code"))
+	if err != nil {
+		oauthError(w, "invalid_grant", "authorization code is invalid or expired", http.StatusBadRequest)
+		return
+	}
+
+	if code.ClientID != client.Id || code.RedirectURI != r.PostForm.Get("redirect_uri") {
+		oauthError(w, "invalid_grant", "authorization code was issued to another client or redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	if !code.VerifyCodeVerifier(r.PostForm.Get("code_verifier")) {
+		oauthError(w, "invalid_grant", "code_verifier does not match the code challenge", http.StatusBadRequest)
+		return
+	}
+
+	user, err := userModel.FindByID(code.UserID)
+	if err != nil {
+		oauthError(w, "invalid_grant", "user not found", http.StatusBadRequest)
+		return
+	}
+
+	grant := session.NewGrant(r)
+	grant.ClientID = client.Id
+	grant.Scope = code.Scope
+	grant.Nonce = code.Nonce
+	grant.AuthTime = code.AuthTime
+
+	tokens, err := session.CreateJWTToken(user, grant)
+	if err == session.ErrUserSuspended {
+		oauthError(w, "invalid_grant", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+func refreshTokenGrant(w http.ResponseWriter, r *http.Request, client *clientModel.Client) {
+	grant := session.NewGrant(r)
+	grant.ClientID = client.Id
+
+	tokens, err := session.RefreshJWTToken(r.PostForm.Get("refresh_token"), grant)
+	if err == sessionModel.ErrRefreshTokenInvalid || err == sessionModel.ErrRefreshTokenReused {
+		oauthError(w, "invalid_grant", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// clientCredentialsGrant issues an access token to the client itself (RFC 6749, section 4.4).
+// No refresh token is issued, the client requests a new token with its credentials.
+func clientCredentialsGrant(w http.ResponseWriter, r *http.Request, client *clientModel.Client) {
+	if !client.IsConfidential() {
+		oauthError(w, "unauthorized_client", "public clients may not use the client_credentials grant", http.StatusBadRequest)
+		return
+	}
+
+	scope := client.AllowedScope(r.PostForm.Get("scope"))
+	if r.PostForm.Get("scope") != "" && scope == "" {
+		oauthError(w, "invalid_scope", "no requested scope is allowed for the client", http.StatusBadRequest)
+		return
+	}
+
+	claims := &sessionModel.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: client.Id},
+		SubjectType:    sessionModel.SUBJECT_TYPE_CLIENT,
+		ClientID:       client.Id,
+		Scope:          scope,
+	}
+	bindToken(r, claims)
+	access, err := sessionModel.NewAccessToken(claims)
+	if err != nil {
+		oauthError(w, "server_error", "", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokens(w, &session.Tokens{
+		Type:      session.TokenType(claims),
+		Access:    access,
+		Scope:     scope,
+		ExpiresIn: claims.ExpiresAt - claims.IssuedAt,
+	})
+}
+
+// bindToken binds the access token to the DPoP key of the request, if any
+func bindToken(r *http.Request, claims *sessionModel.Claims) {
+	if jkt := middleware.GetDPoPThumbprint(r.Context()); jkt != "" {
+		claims.Cnf = &sessionModel.Confirmation{JKT: jkt}
+	}
+}
+
+func writeTokens(w http.ResponseWriter, tokens *session.Tokens) {
+	writeJSON(w, TokenResponse{
+		AccessToken:  tokens.Access,
+		TokenType:    tokens.Type,
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.Refresh,
+		IDToken:      tokens.ID,
+		Scope:        tokens.Scope,
+	}, http.StatusOK)
+}
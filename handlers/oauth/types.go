@@ -14,5 +14,5 @@ type UserGoogle struct {
 	Locale        string `json:"locale"`
 	Name          string `json:"name"`
 	Picture       string `json:"picture"`
-	VerifiedEmail bool   `json:"veriefied_email"`
+	VerifiedEmail bool   `json:"verified_email"`
 }
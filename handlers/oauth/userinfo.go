@@ -0,0 +1,29 @@
+package oauth
+
+import (
+	"net/http"
+
+	"github.com/handymesh/hyshAuthService/handlers/session"
+	"github.com/handymesh/hyshAuthService/middleware"
+	userModel "github.com/handymesh/hyshAuthService/models/user"
+)
+
+// UserInfo returns the claims about the signed-in user
+// allowed by the scope of the access token (OpenID Connect Core, section 5.3)
+func UserInfo(w http.ResponseWriter, r *http.Request) {
+	principal := middleware.GetPrincipal(r.Context())
+	if !principal.HasScope("openid") {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		oauthError(w, "insufficient_scope", "openid scope is required", http.StatusForbidden)
+		return
+	}
+
+	user, err := userModel.FindByID(principal.Subject)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		oauthError(w, "invalid_token", "user not found", http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, session.NewIDClaims(user, principal.Scope()), http.StatusOK)
+}
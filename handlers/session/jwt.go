@@ -1,26 +1,229 @@
 package session
 
 import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/handymesh/hyshAuthService/middleware"
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
 	"github.com/handymesh/hyshAuthService/models/session"
-	"time"
+	userModel "github.com/handymesh/hyshAuthService/models/user"
+	"github.com/handymesh/hyshAuthService/utils"
 )
 
-func CreateJWTToken() (string, string, error) {
-	// Create JWT token
-	timeTTL := time.Minute * 5
-	timeDuration := time.Now().Add(timeTTL).Unix()
+var (
+	// Get configuration
+	USER_DEFAULT_SCOPE = utils.Getenv("USER_DEFAULT_SCOPE", "openid profile email")
+	DEFAULT_CLIENT_ID  = utils.Getenv("DEFAULT_CLIENT_ID", "hysh-web")
 
-	// get access token
-	tokenString, err := sessionModel.NewAccessToken(timeDuration)
+	ErrUserSuspended = errors.New("user suspended")
+)
+
+// Tokens issued for a session. ID is set when the openid scope was granted,
+// Type is DPoP for tokens bound to the key of the client.
+type Tokens struct {
+	Type      string
+	Access    string
+	Refresh   string
+	ID        string
+	Scope     string
+	ExpiresIn int64
+}
+
+// NewGrant reads the device of the request, the client is
+// set by the caller once it is authenticated
+func NewGrant(r *http.Request) sessionModel.Grant {
+	// RemoteAddr is already set to the real IP by the RealIP middleware
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		return "", "", err
+		ip = r.RemoteAddr
+	}
+
+	device := r.Header.Get("X-Device-Name")
+	if device == "" {
+		device = deviceFromUserAgent(r.UserAgent())
+	}
+
+	return sessionModel.Grant{
+		Scope:     USER_DEFAULT_SCOPE,
+		Device:    device,
+		UserAgent: r.UserAgent(),
+		IP:        ip,
+		JKT:       middleware.GetDPoPThumbprint(r.Context()),
+	}
+}
+
+// FirstPartyGrant reads the grant of a request to the login endpoints.
+// The client in X-Client-Id must be a first-party client, a registered
+// confidential client also sends its secret in X-Client-Secret.
+func FirstPartyGrant(r *http.Request) (sessionModel.Grant, error) {
+	grant := NewGrant(r)
+
+	clientId := r.Header.Get("X-Client-Id")
+	if clientId == "" {
+		clientId = DEFAULT_CLIENT_ID
+	}
+	if !sessionModel.IsFirstParty(clientId) {
+		return grant, clientModel.ErrInvalidClient
+	}
+
+	client, err := clientModel.FindByID(clientId)
+	if err != nil && err != clientModel.ErrClientNotFound {
+		return grant, err
+	}
+	if client != nil && client.IsConfidential() {
+		_, err = clientModel.Authenticate(clientId, r.Header.Get("X-Client-Secret"))
+		if err != nil {
+			return grant, clientModel.ErrInvalidClient
+		}
+	}
+
+	grant.ClientID = clientId
+	return grant, nil
+}
+
+// CreateJWTToken starts a session for the user and issues its tokens
+func CreateJWTToken(user *userModel.User, grant sessionModel.Grant) (*Tokens, error) {
+	if !user.IsActive() {
+		return nil, ErrUserSuspended
+	}
+
+	session, err := sessionModel.NewSession(user.Id, grant)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := newTokens(user, session, grant.Nonce)
+	if err != nil {
+		return nil, err
 	}
 
 	// get refresh token
-	refreshToken, err := sessionModel.NewRefreshToken(timeTTL)
+	tokens.Refresh, err = sessionModel.NewRefreshToken(session)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// RefreshJWTToken rotates the refresh token and issues new tokens for
+// the user the refresh token belongs to, with the user's current roles
+func RefreshJWTToken(token string, grant sessionModel.Grant) (*Tokens, error) {
+	session, refreshToken, err := sessionModel.RotateRefreshToken(token, grant)
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+
+	// Deleted and suspended users lose their sessions
+	user, err := userModel.FindByID(session.UserID)
+	if err != nil || !user.IsActive() {
+		err = sessionModel.RevokeSession(session.UserID, session.ID)
+		if err != nil {
+			log.Error("Fail revoke session: ", err)
+		}
+		return nil, sessionModel.ErrRefreshTokenInvalid
+	}
+
+	tokens, err := newTokens(user, session, "")
+	if err != nil {
+		return nil, err
+	}
+	tokens.Refresh = refreshToken
+
+	return tokens, nil
+}
+
+// newTokens issues the access token and, for the openid scope, the ID token of the session
+func newTokens(user *userModel.User, session *sessionModel.Session, nonce string) (*Tokens, error) {
+	claims := &sessionModel.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: user.Id},
+		SessionID:      session.ID,
+		ClientID:       session.ClientID,
+		Scope:          session.Scope,
+	}
+	// Third-party clients act with their scope only, never with the user's roles
+	if sessionModel.IsFirstParty(session.ClientID) {
+		claims.Roles = user.GetRoles()
+	}
+	if session.JKT != "" {
+		claims.Cnf = &sessionModel.Confirmation{JKT: session.JKT}
+	}
+	access, err := sessionModel.NewAccessToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := &Tokens{
+		Type:      TokenType(claims),
+		Access:    access,
+		Scope:     session.Scope,
+		ExpiresIn: claims.ExpiresAt - claims.IssuedAt,
+	}
+
+	if hasScope(session.Scope, "openid") {
+		idClaims := NewIDClaims(user, session.Scope)
+		idClaims.Nonce = nonce
+		idClaims.AuthTime = session.AuthTime.Unix()
+		idClaims.SessionID = session.ID
+
+		tokens.ID, err = sessionModel.NewIDToken(idClaims, session.ClientID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tokens, nil
+}
+
+// TokenType is the token_type of an access token with the claims
+func TokenType(claims *sessionModel.Claims) string {
+	if claims.KeyThumbprint() != "" {
+		return middleware.SCHEME_DPOP
+	}
+	return middleware.SCHEME_BEARER
+}
+
+// NewIDClaims returns the user claims released for the scope
+func NewIDClaims(user *userModel.User, scope string) *sessionModel.IDClaims {
+	claims := &sessionModel.IDClaims{
+		StandardClaims: jwt.StandardClaims{Subject: user.Id},
+	}
+
+	if hasScope(scope, "email") && user.Email != nil {
+		claims.Email = *user.Email
+		claims.EmailVerified = &user.EmailVerified
+	}
+
+	if hasScope(scope, "profile") {
+		claims.Name = user.Fullname
+		claims.Locale = user.Locale
+	}
+
+	return claims
+}
+
+func hasScope(scope string, value string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == value {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceFromUserAgent names the platform of a browser or app
+func deviceFromUserAgent(userAgent string) string {
+	platforms := []string{"iPhone", "iPad", "Android", "Windows", "Mac OS X", "CrOS", "Linux"}
+	for _, platform := range platforms {
+		if strings.Contains(userAgent, platform) {
+			return platform
+		}
 	}
 
-	return tokenString, refreshToken, nil
+	return "Unknown"
 }
@@ -2,27 +2,24 @@ package session
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 
 	// "go.mongodb.org/mongo-driver/bson"
 	"github.com/go-chi/chi"
 	chiMiddleware "github.com/go-chi/chi/middleware"
+	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 
-	pb "github.com/handymesh/hyshAuthService/grpc/mail"
-	grpcServer "github.com/handymesh/hyshAuthService/grpc/server"
 	"github.com/handymesh/hyshAuthService/handlers/user"
 	"github.com/handymesh/hyshAuthService/middleware"
 	sessionModel "github.com/handymesh/hyshAuthService/models/session"
 	userModel "github.com/handymesh/hyshAuthService/models/user"
 	"github.com/handymesh/hyshAuthService/utils"
 	"github.com/handymesh/hyshAuthService/utils/crypto"
+	"github.com/handymesh/hyshAuthService/utils/mailer"
 )
 
 var log = logrus.New()
@@ -38,16 +35,29 @@ func init() {
 // Routes creates a REST router
 func Routes() chi.Router {
 	r := chi.NewRouter()
-	r.Use(middleware.Captcha)
 	r.Use(chiMiddleware.AllowContentType("application/json"))
 
-	r.Get("/debug/{token}", Debug)
-	r.Post("/", Login)
-	r.Post("/new", Registration)
-	r.Post("/recovery", Recovery)
-	r.Post("/recovery/{token}", RecoveryByToken)
-	r.Post("/refresh", Refresh)
-	r.Delete("/", Logout)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Captcha)
+
+		r.Get("/debug/{token}", Debug)
+		r.With(middleware.DPoP).Post("/", Login)
+		r.Post("/new", Registration)
+		r.Post("/recovery", Recovery)
+		r.Post("/recovery/{token}", RecoveryByToken)
+		r.With(middleware.DPoP).Post("/refresh", Refresh)
+		r.Delete("/", Logout)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.CheckAuth)
+		r.Use(middleware.RequireFirstParty)
+
+		r.Get("/sessions", ListSessions)
+		r.Get("/sessions/{sessionId}", GetSession)
+		r.Delete("/sessions", RevokeOtherSessions)
+		r.Delete("/sessions/{sessionId}", RevokeSession)
+	})
 
 	return r
 }
@@ -69,6 +79,12 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// OpenID Connect nonce to bind the ID token to the client's request
+	var authRequest struct {
+		Nonce string `json:"nonce"`
+	}
+	json.Unmarshal(b, &authRequest)
+
 	var passwordUser = user.Password
 	var searchUser = userModel.User{Email: user.Email}
 	user, err = userModel.FindOne(searchUser)
@@ -84,24 +100,33 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create JWT token
-	tokenString, refreshToken, err := CreateJWTToken()
+	grant, err := FirstPartyGrant(r)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusUnauthorized)
+		return
+	}
+	grant.Nonce = authRequest.Nonce
+	tokens, err := CreateJWTToken(user, grant)
 	if err != nil {
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Authorization", tokenString)
+	w.Header().Set("Authorization", tokens.Access)
 	w.WriteHeader(http.StatusCreated)
 
 	type UserOutput struct {
 		User struct {
-			ID     string `json:"Id"`
-			Email  string `json:"Email"`
-			Gender string `json:"Gender"`
+			ID     string   `json:"Id"`
+			Email  string   `json:"Email"`
+			Gender string   `json:"Gender"`
+			Roles  []string `json:"Roles"`
 		} `json:"user"`
 		Tokens struct {
+			Type    string `json:"type"`
 			Access  string `json:"access"`
 			Refresh string `json:"refresh"`
+			ID      string `json:"id,omitempty"`
 		} `json:"tokens"`
 	}
 
@@ -110,8 +135,11 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	userOutput.User.ID = user.Id
 	userOutput.User.Email = *user.Email
 	userOutput.User.Gender = user.Gender
-	userOutput.Tokens.Access = tokenString
-	userOutput.Tokens.Refresh = refreshToken
+	userOutput.User.Roles = user.GetRoles()
+	userOutput.Tokens.Type = tokens.Type
+	userOutput.Tokens.Access = tokens.Access
+	userOutput.Tokens.Refresh = tokens.Refresh
+	userOutput.Tokens.ID = tokens.ID
 
 	response := utils.ResponseType{
 		Data:    userOutput,
@@ -147,33 +175,30 @@ func Registration(w http.ResponseWriter, r *http.Request) {
 func Logout(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	var Authorization = r.Header.Get("Authorization")
-	if Authorization == "" {
-		w.WriteHeader(http.StatusUnauthorized)
-		utils.Error(w, errors.New(`"not auth"`), http.StatusBadRequest)
-		return
-	}
-
-	token, err := sessionModel.VerifyToken(Authorization)
+	// DPoP-bound tokens come with the DPoP scheme and a proof of their key
+	claims, err := middleware.Authenticate(r)
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
 		return
 	}
 
-	if !token.Valid {
-		w.WriteHeader(http.StatusUnauthorized)
-		utils.Error(w, errors.New(`"token invalid"`), http.StatusBadRequest)
-		return
-	}
-
-	err = sessionModel.Delete(Authorization)
+	err = sessionModel.RevokeAccessToken(claims)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
 		return
 	}
 
+	// End the session so its refresh token stops working too
+	if claims.SessionID != "" {
+		err = sessionModel.RevokeSession(claims.Subject, claims.SessionID)
+		if err != nil && err != sessionModel.ErrSessionNotFound {
+			utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+			return
+		}
+	}
+
 	response := utils.ResponseType{
 		Data:    "",
 		Status:  http.StatusOK,
@@ -194,39 +219,47 @@ func Logout(w http.ResponseWriter, r *http.Request) {
 func Refresh(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	var TOKEN_REFRESH = r.Header.Get("Authorization")
+	// The DPoP middleware checked the proof, the scheme only tells how the token is sent
+	_, TOKEN_REFRESH := middleware.AccessToken(r)
 	if TOKEN_REFRESH == "" {
 		w.WriteHeader(http.StatusUnauthorized)
 		utils.Error(w, errors.New(`"not auth"`), http.StatusBadRequest)
 		return
 	}
 
-	// Chech REFRESH TOKEN
-	status, err := sessionModel.CheckRefreshToken(TOKEN_REFRESH)
-	if err != nil || status != true {
+	grant, err := FirstPartyGrant(r)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusUnauthorized)
+		return
+	}
+
+	// Rotate REFRESH TOKEN and create JWT token
+	tokens, err := RefreshJWTToken(TOKEN_REFRESH, grant)
+	if err == sessionModel.ErrRefreshTokenInvalid || err == sessionModel.ErrRefreshTokenReused {
 		w.WriteHeader(http.StatusUnauthorized)
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
 		return
 	}
-
-	// Create JWT token
-	tokenString, refreshToken, err := CreateJWTToken()
 	if err != nil {
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Authorization", tokenString)
+	w.Header().Set("Authorization", tokens.Access)
 	type Data struct {
 		Tokens struct {
+			Type    string `json:"type"`
 			Access  string `json:"access"`
 			Refresh string `json:"refresh"`
+			ID      string `json:"id,omitempty"`
 		} `json:"tokens"`
 	}
 
 	var data Data
-	data.Tokens.Access = tokenString
-	data.Tokens.Refresh = refreshToken
+	data.Tokens.Type = tokens.Type
+	data.Tokens.Access = tokens.Access
+	data.Tokens.Refresh = tokens.Refresh
+	data.Tokens.ID = tokens.ID
 
 	response := utils.ResponseType{
 		Data:    data,
@@ -247,6 +280,9 @@ func Refresh(w http.ResponseWriter, r *http.Request) {
 func Recovery(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	span := opentracing.GlobalTracer().StartSpan("POST /auth/recovery")
+	defer span.Finish()
+
 	b, err := ioutil.ReadAll(r.Body)
 	defer r.Body.Close()
 	if err != nil {
@@ -261,6 +297,12 @@ func Recovery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	grant, err := FirstPartyGrant(r)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusUnauthorized)
+		return
+	}
+
 	// search user by mail
 	searchUser := userModel.User{}
 	searchUser.Email = user.Email
@@ -270,23 +312,50 @@ func Recovery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// get refresh token
-	recoveryLink, err := sessionModel.NewRecoveryLink(user.Id)
+	// A retried request with the same Idempotency-Key sends a single mail
+	// and does not issue another link
+	var idempotencyKey string
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if len(key) > 255 {
+			utils.Error(w, errors.New(`"Idempotency-Key is too long"`), http.StatusBadRequest)
+			return
+		}
+		idempotencyKey = "recovery:" + user.Id + ":" + key
+
+		reserved, err := sessionModel.ReserveRecoveryRequest(idempotencyKey, grant.ClientID)
+		if err != nil {
+			utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+			return
+		}
+		if !reserved {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+			return
+		}
+	}
+
+	// get recovery link
+	recoveryLink, err := sessionModel.NewRecoveryLink(user.Id, grant.ClientID)
 	if err != nil {
+		releaseRecoveryRequest(idempotencyKey)
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
 		return
 	}
 
 	// Send mail
-	conn := grpcServer.GetConnClient()
-	c := pb.NewMailClient(conn)
-	_, err = c.SendMail(context.Background(), &pb.MailRequest{
-		Template: "recovery",
-		Mail:     *user.Email,
-		Url:      "http://localhost:3000/recovery/" + recoveryLink,
+	err = mailer.Send(opentracing.ContextWithSpan(r.Context(), span), mailer.Message{
+		Template:       mailer.TEMPLATE_RECOVERY,
+		To:             *user.Email,
+		URL:            "http://localhost:3000/recovery/" + recoveryLink,
+		Locale:         user.Locale,
+		Variables:      map[string]string{"fullname": user.Fullname},
+		RequestID:      chiMiddleware.GetReqID(r.Context()),
+		Priority:       mailer.PRIORITY_HIGH,
+		IdempotencyKey: idempotencyKey,
 	})
 	if err != nil {
-		fmt.Printf("error %v", err.Error())
+		log.Error("Fail send recovery mail: ", err)
+		releaseRecoveryRequest(idempotencyKey)
 		utils.Error(w, errors.New("\"failed to send message\""), http.StatusBadRequest)
 		return
 	}
@@ -295,6 +364,18 @@ func Recovery(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{}`))
 }
 
+// releaseRecoveryRequest lets the client retry a failed recovery request with the same key
+func releaseRecoveryRequest(idempotencyKey string) {
+	if idempotencyKey == "" {
+		return
+	}
+
+	err := sessionModel.ReleaseRecoveryRequest(idempotencyKey)
+	if err != nil {
+		log.Error("Fail release recovery request: ", err)
+	}
+}
+
 func RecoveryByToken(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -318,14 +399,14 @@ func RecoveryByToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userId, _ := sessionModel.GetValueByKey(user.RecoveryToken)
-	if len(userId) == 0 {
+	link, err := sessionModel.ConsumeRecoveryLink(user.RecoveryToken)
+	if err != nil {
 		utils.Error(w, errors.New(`"not found"`), http.StatusBadRequest)
 		return
 	}
 
-	t, err := primitive.ObjectIDFromHex(userId)
-	user.Id = t.String()
+	user.Id = link.UserID
+	user.StripPrivileged()
 	user.Password, _ = crypto.HashPassword(user.Password)
 
 	user, err = userModel.Update(user)
@@ -333,10 +414,11 @@ func RecoveryByToken(w http.ResponseWriter, r *http.Request) {
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
 		return
 	}
-	err = sessionModel.Delete(user.RecoveryToken)
+
+	// Sign out everywhere with the old password
+	err = sessionModel.RevokeUserTokens(link.UserID)
 	if err != nil {
-		utils.Error(w, errors.New("not found"), http.StatusBadRequest)
-		return
+		log.Error("Fail revoke user tokens: ", err)
 	}
 	// TODO: Send mail (theme: New password)
 
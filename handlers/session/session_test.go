@@ -0,0 +1,124 @@
+package session
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dgrijalva/jwt-go"
+	goredis "github.com/go-redis/redis"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils/jose"
+)
+
+// newTestToken signs an access token of the claims with a key
+// the validator accepts for the duration of the test
+func newTestToken(t *testing.T, claims sessionModel.Claims) string {
+	key, err := sessionModel.NewSigningKey("ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFunc := sessionModel.AccessTokens.Key
+	sessionModel.AccessTokens.Key = func(kid string) *sessionModel.SigningKey {
+		if kid == key.Kid {
+			return key
+		}
+		return keyFunc(kid)
+	}
+	t.Cleanup(func() { sessionModel.AccessTokens.Key = keyFunc })
+
+	now := time.Now()
+	claims.Id = now.Format(time.RFC3339Nano)
+	claims.Issuer = sessionModel.ISSUER
+	claims.Audience = sessionModel.AUDIENCE
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(time.Minute).Unix()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.Kid
+	signed, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestLogoutWithDPoP(t *testing.T) {
+	server := miniredis.RunT(t)
+	redis.Redis = goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	defer redis.Redis.Close()
+
+	dpopKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := jose.NewJWK(&dpopKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jkt, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	access := newTestToken(t, sessionModel.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: "5c3a1b2e9d4f6a7b8c9d0e1f"},
+		ClientID:       DEFAULT_CLIENT_ID,
+		Cnf:            &sessionModel.Confirmation{JKT: jkt},
+	})
+
+	newProof := func(jti string) string {
+		sum := sha256.Sum256([]byte(access))
+		proof := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"jti": jti,
+			"htm": http.MethodDelete,
+			"htu": sessionModel.ISSUER + "/auth/",
+			"iat": time.Now().Unix(),
+			"ath": base64.RawURLEncoding.EncodeToString(sum[:]),
+		})
+		proof.Header["typ"] = sessionModel.DPOP_PROOF_TYPE
+		proof.Header["jwk"] = jwk
+		signed, err := proof.SignedString(dpopKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		proof         string
+		status        int
+	}{
+		{"bearer scheme", "Bearer " + access, newProof("1"), http.StatusUnauthorized},
+		{"without proof", "DPoP " + access, "", http.StatusUnauthorized},
+		{"DPoP scheme with proof", "DPoP " + access, newProof("2"), http.StatusOK},
+		{"revoked token", "DPoP " + access, newProof("3"), http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodDelete, "/auth/", nil)
+		req.Header.Set("Authorization", test.authorization)
+		if test.proof != "" {
+			req.Header.Set("DPoP", test.proof)
+		}
+
+		w := httptest.NewRecorder()
+		Logout(w, req)
+		if w.Code != test.status {
+			t.Errorf("[%s] got %v %s want %v", test.name, w.Code, w.Body.String(), test.status)
+		}
+	}
+}
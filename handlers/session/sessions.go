@@ -0,0 +1,112 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/handymesh/hyshAuthService/middleware"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+type SessionOutput struct {
+	sessionModel.Session
+	Current bool `json:"current"`
+}
+
+// ListSessions returns the devices the caller is signed in on
+func ListSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	principal := middleware.GetPrincipal(r.Context())
+
+	sessions, err := sessionModel.ListSessions(principal.Subject)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	output := []SessionOutput{}
+	for _, session := range sessions {
+		output = append(output, SessionOutput{
+			Session: session,
+			Current: session.ID == principal.SessionID,
+		})
+	}
+
+	writeResponse(w, output, http.StatusOK)
+}
+
+// GetSession returns one session of the caller
+func GetSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	principal := middleware.GetPrincipal(r.Context())
+	var sessionId = chi.URLParam(r, "sessionId")
+
+	session, err := sessionModel.GetSession(sessionId)
+	if err != nil || session.UserID != principal.Subject {
+		utils.Error(w, errors.New(`"not found"`), http.StatusNotFound)
+		return
+	}
+
+	writeResponse(w, SessionOutput{
+		Session: *session,
+		Current: session.ID == principal.SessionID,
+	}, http.StatusOK)
+}
+
+// RevokeSession signs the caller out of one session
+func RevokeSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	principal := middleware.GetPrincipal(r.Context())
+	var sessionId = chi.URLParam(r, "sessionId")
+
+	err := sessionModel.RevokeSession(principal.Subject, sessionId)
+	if err == sessionModel.ErrSessionNotFound {
+		utils.Error(w, errors.New(`"not found"`), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, "", http.StatusOK)
+}
+
+// RevokeOtherSessions signs the caller out of every session but the current one
+func RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	principal := middleware.GetPrincipal(r.Context())
+
+	err := sessionModel.RevokeOtherSessions(principal.Subject, principal.SessionID)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, "", http.StatusOK)
+}
+
+func writeResponse(w http.ResponseWriter, data interface{}, status int) {
+	response := utils.ResponseType{
+		Data:    data,
+		Status:  status,
+		Message: "Success",
+	}
+
+	output, err := json.Marshal(response)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(output)
+}
@@ -3,7 +3,6 @@ package user
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 
@@ -13,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/handymesh/hyshAuthService/middleware"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
 	userModel "github.com/handymesh/hyshAuthService/models/user"
 	"github.com/handymesh/hyshAuthService/utils"
 	"github.com/handymesh/hyshAuthService/utils/crypto"
@@ -32,6 +32,7 @@ func init() {
 func Routes() chi.Router {
 	r := chi.NewRouter()
 	r.Use(middleware.CheckAuth)
+	r.Use(middleware.RequireFirstParty)
 	r.Use(chiMiddleware.AllowContentType("application/json"))
 
 	r.Get("/", List)
@@ -91,6 +92,11 @@ func Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only admins assign roles, self-registered users get the default one
+	if principal := middleware.GetPrincipal(r.Context()); principal == nil || !principal.HasRole(userModel.ROLE_ADMIN) {
+		user.StripPrivileged()
+	}
+
 	is_err := CheckUniqueUser(w, user)
 	if is_err {
 		return
@@ -107,6 +113,8 @@ func Create(w http.ResponseWriter, r *http.Request) {
 		Email:     user.Email,
 		Gender:    user.Gender,
 		Fullname:  user.Fullname,
+		Roles:     user.GetRoles(),
+		Status:    user.Status,
 		Profiles:  user.Profiles,
 		CreatedAt: *user.CreatedAt,
 		UpdatedAt: *user.UpdatedAt,
@@ -154,15 +162,26 @@ func Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var searchUser = userModel.User{Email: user.Email}
-	_, err = userModel.FindOne(searchUser)
+	principal := middleware.GetPrincipal(r.Context())
+	if principal == nil || (principal.Subject != userId && !principal.HasRole(userModel.ROLE_ADMIN)) {
+		utils.Error(w, errors.New(`"forbidden"`), http.StatusForbidden)
+		return
+	}
+
+	_, err = userModel.FindByID(userId)
 	if err != nil {
 		utils.Error(w, errors.New(`{"Id":"User not exists"}`), http.StatusBadRequest)
 		return
 	}
 
+	if !principal.HasRole(userModel.ROLE_ADMIN) {
+		user.StripPrivileged()
+	}
+
 	user.Id = userId
-	user.Password, _ = crypto.HashPassword(user.Password)
+	if user.Password != "" {
+		user.Password, _ = crypto.HashPassword(user.Password)
+	}
 
 	user, err = userModel.Update(user)
 	if err != nil {
@@ -170,6 +189,13 @@ func Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if user.Status == userModel.USER_STATUS_SUSPENDED {
+		err = sessionModel.RevokeUserTokens(userId)
+		if err != nil {
+			log.Error("Fail revoke user tokens: ", err)
+		}
+	}
+
 	output, err := json.Marshal(&user)
 	if err != nil {
 		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
@@ -187,10 +213,13 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 
 	var userId = chi.URLParam(r, "userId")
 
-	fmt.Println("userID", userId)
+	principal := middleware.GetPrincipal(r.Context())
+	if principal == nil || (principal.Subject != userId && !principal.HasRole(userModel.ROLE_ADMIN)) {
+		utils.Error(w, errors.New(`"forbidden"`), http.StatusForbidden)
+		return
+	}
 
-	var searchUser = userModel.User{Id: userId}
-	_, err := userModel.FindOne(searchUser)
+	_, err := userModel.FindByID(userId)
 	if err != nil {
 		utils.Error(w, errors.New(`{"Id":"User not exists"}`), http.StatusBadRequest)
 		return
@@ -202,5 +231,10 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = sessionModel.RevokeUserTokens(userId)
+	if err != nil {
+		log.Error("Fail revoke user tokens: ", err)
+	}
+
 	w.Write([]byte(`{"id": "` + userId + `"}`))
 }
@@ -0,0 +1,93 @@
+package wellknown
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+// Discovery is the OpenID Provider metadata (OpenID Connect Discovery, section 3)
+type Discovery struct {
+	Issuer                                 string   `json:"issuer"`
+	AuthorizationEndpoint                  string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                          string   `json:"token_endpoint,omitempty"`
+	UserinfoEndpoint                       string   `json:"userinfo_endpoint"`
+	JwksURI                                string   `json:"jwks_uri"`
+	IntrospectionEndpoint                  string   `json:"introspection_endpoint"`
+	RevocationEndpoint                     string   `json:"revocation_endpoint"`
+	RegistrationEndpoint                   string   `json:"registration_endpoint,omitempty"`
+	DeviceAuthorizationEndpoint            string   `json:"device_authorization_endpoint,omitempty"`
+	ResponseTypesSupported                 []string `json:"response_types_supported"`
+	GrantTypesSupported                    []string `json:"grant_types_supported,omitempty"`
+	CodeChallengeMethodsSupported          []string `json:"code_challenge_methods_supported,omitempty"`
+	SubjectTypesSupported                  []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported       []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                        []string `json:"scopes_supported"`
+	ClaimsSupported                        []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported      []string `json:"token_endpoint_auth_methods_supported"`
+	IntrospectionEndpointAuthMethods       []string `json:"introspection_endpoint_auth_methods_supported"`
+	RevocationEndpointAuthMethodsSupported []string `json:"revocation_endpoint_auth_methods_supported"`
+	TokenEndpointAuthSigningAlgsSupported  []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+	DPoPSigningAlgValuesSupported          []string `json:"dpop_signing_alg_values_supported,omitempty"`
+}
+
+// OpenIDConfiguration publishes the provider metadata
+func OpenIDConfiguration(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	issuer := sessionModel.ISSUER
+	authMethods := []string{"client_secret_basic", "client_secret_post", "private_key_jwt"}
+
+	algs := []string{}
+	seen := map[string]bool{}
+	for _, key := range sessionModel.SigningKeys() {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+
+	discovery := Discovery{
+		Issuer:                      issuer,
+		AuthorizationEndpoint:       issuer + "/oauth/authorize",
+		TokenEndpoint:               issuer + "/oauth/token",
+		UserinfoEndpoint:            issuer + "/oauth/userinfo",
+		JwksURI:                     issuer + "/.well-known/jwks.json",
+		IntrospectionEndpoint:       issuer + "/oauth/introspect",
+		RevocationEndpoint:          issuer + "/oauth/revoke",
+		RegistrationEndpoint:        issuer + "/oauth/register",
+		DeviceAuthorizationEndpoint: issuer + "/oauth/device_authorization",
+		ResponseTypesSupported:      []string{"code"},
+		GrantTypesSupported: []string{
+			clientModel.GRANT_AUTHORIZATION_CODE,
+			clientModel.GRANT_REFRESH_TOKEN,
+			clientModel.GRANT_CLIENT_CREDENTIALS,
+			clientModel.GRANT_DEVICE_CODE,
+			clientModel.GRANT_TOKEN_EXCHANGE,
+		},
+		CodeChallengeMethodsSupported:          []string{sessionModel.CODE_CHALLENGE_S256},
+		SubjectTypesSupported:                  []string{"public"},
+		IDTokenSigningAlgValuesSupported:       algs,
+		ScopesSupported:                        []string{"openid", "profile", "email"},
+		ClaimsSupported:                        []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "sid", "email", "email_verified", "name", "locale"},
+		TokenEndpointAuthMethodsSupported:      append(authMethods, "none"),
+		IntrospectionEndpointAuthMethods:       authMethods,
+		RevocationEndpointAuthMethodsSupported: authMethods,
+		TokenEndpointAuthSigningAlgsSupported:  []string{"RS256", "ES256", "EdDSA"},
+		DPoPSigningAlgValuesSupported:          sessionModel.DPoPAlgs,
+	}
+
+	output, err := json.Marshal(discovery)
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
@@ -0,0 +1,48 @@
+package wellknown
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+var log = logrus.New()
+
+func init() {
+	// Logging =================================================================
+	// Setup the logger backend using Sirupsen/logrus and configure
+	// it to use a custom JSONFormatter. See the logrus docs for how to
+	// configure the backend at github.com/Sirupsen/logrus
+	log.Formatter = new(logrus.JSONFormatter)
+}
+
+// Routes creates a router for the public discovery documents
+func Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/jwks.json", JWKS)
+	r.Get("/openid-configuration", OpenIDConfiguration)
+
+	return r
+}
+
+// JWKS publishes the public keys used to sign access tokens
+func JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	output, err := json.Marshal(sessionModel.JWKS())
+	if err != nil {
+		utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	userModel "github.com/handymesh/hyshAuthService/models/user"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+// Admin allows requests carrying the ADMIN_API_KEY in the X-Admin-Key header
+// or an access token of a user with the admin role.
+func Admin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Get configuration =======================================================
+		ADMIN_API_KEY := utils.Getenv("ADMIN_API_KEY", "")
+
+		var key = r.Header.Get("X-Admin-Key")
+		if key != "" {
+			if ADMIN_API_KEY == "" || subtle.ConstantTimeCompare([]byte(key), []byte(ADMIN_API_KEY)) != 1 {
+				utils.Error(w, errors.New(`"forbidden"`), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", challenge(err))
+			utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusUnauthorized)
+			return
+		}
+
+		principal := claims.Principal()
+		if !principal.HasRole(userModel.ROLE_ADMIN) {
+			utils.Error(w, errors.New(`"forbidden"`), http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalCtxKey{}, principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	})
+}
@@ -1,37 +1,159 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strings"
 
 	sessionModel "github.com/handymesh/hyshAuthService/models/session"
 	"github.com/handymesh/hyshAuthService/utils"
 )
 
+const (
+	SCHEME_BEARER = "Bearer"
+	SCHEME_DPOP   = "DPoP"
+)
+
+var (
+	ErrNotAuth       = errors.New("not auth")
+	ErrTokenBound    = errors.New("token is bound to a DPoP key")
+	ErrTokenNotBound = errors.New("token is not bound to a DPoP key")
+)
+
+type principalCtxKey struct{}
+
+// CheckAuth verifies the access token and puts the caller
+// into the request context, see GetPrincipal
 func CheckAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		var Authorization = r.Header.Get("Authorization")
-		if Authorization == "" {
-			w.WriteHeader(http.StatusUnauthorized)
-			utils.Error(w, errors.New(`"not auth"`), http.StatusBadRequest)
+		claims, err := Authenticate(r)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if err == sessionModel.ErrTokenMalformed {
+				status = http.StatusBadRequest
+			}
+
+			w.Header().Set("WWW-Authenticate", challenge(err))
+			utils.Error(w, errors.New(`"`+err.Error()+`"`), status)
 			return
 		}
 
-		token, err := sessionModel.VerifyToken(Authorization)
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			utils.Error(w, errors.New(`"`+err.Error()+`"`), http.StatusBadRequest)
+		ctx := context.WithValue(r.Context(), principalCtxKey{}, claims.Principal())
+		next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	})
+}
+
+// Authenticate verifies the access token of the request. DPoP-bound tokens
+// must come with the DPoP scheme and a proof of their key.
+func Authenticate(r *http.Request) (*sessionModel.Claims, error) {
+	scheme, token := AccessToken(r)
+	if token == "" {
+		return nil, ErrNotAuth
+	}
+
+	claims, err := sessionModel.ParseAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyTokenBinding(r, scheme, token, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// GetPrincipal returns the caller set by CheckAuth, or nil
+func GetPrincipal(ctx context.Context) *sessionModel.Principal {
+	principal, _ := ctx.Value(principalCtxKey{}).(*sessionModel.Principal)
+	return principal
+}
+
+// AccessToken reads the scheme and the token from the Authorization header.
+// A token without scheme is taken as a bearer token.
+func AccessToken(r *http.Request) (string, string) {
+	var Authorization = r.Header.Get("Authorization")
+	for _, scheme := range []string{SCHEME_BEARER, SCHEME_DPOP} {
+		prefix := scheme + " "
+		if len(Authorization) > len(prefix) && strings.EqualFold(Authorization[:len(prefix)], prefix) {
+			return scheme, strings.TrimSpace(Authorization[len(prefix):])
+		}
+	}
+	return SCHEME_BEARER, Authorization
+}
+
+// BearerToken reads the token from the Authorization header,
+// with or without the "Bearer" scheme
+func BearerToken(r *http.Request) string {
+	var Authorization = r.Header.Get("Authorization")
+	if len(Authorization) > 7 && strings.EqualFold(Authorization[:7], "Bearer ") {
+		return strings.TrimSpace(Authorization[7:])
+	}
+	return Authorization
+}
+
+// RequireUser rejects service tokens on endpoints acting on behalf of a user.
+// It must run after CheckAuth.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := GetPrincipal(r.Context())
+		if principal == nil || principal.IsClient() {
+			utils.Error(w, errors.New(`"user token required"`), http.StatusForbidden)
 			return
 		}
 
-		if token.Valid {
-			next.ServeHTTP(w, r)
-		} else {
-			w.WriteHeader(http.StatusUnauthorized)
-			utils.Error(w, errors.New(`"token invalid"`), http.StatusBadRequest)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireFirstParty rejects service tokens and user tokens of third-party clients
+// on endpoints managing the account of the user. It must run after CheckAuth.
+func RequireFirstParty(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := GetPrincipal(r.Context())
+		if principal == nil || !principal.IsFirstPartyUser() {
+			utils.Error(w, errors.New(`"first-party user token required"`), http.StatusForbidden)
+			return
 		}
-		return
+
+		next.ServeHTTP(w, r)
 	})
 }
+
+// challenge is the WWW-Authenticate header for a failed authentication.
+// The error is invalid_request for a malformed token and invalid_token
+// otherwise (RFC 6750, section 3.1), error_description tells the cause.
+func challenge(err error) string {
+	algs := ` algs="` + strings.Join(sessionModel.DPoPAlgs, " ") + `"`
+
+	var code, description string
+	switch err {
+	case ErrNotAuth:
+		return SCHEME_BEARER + ` realm="hysh", ` + SCHEME_DPOP + algs
+	case ErrTokenBound, sessionModel.ErrDPoPProofInvalid, sessionModel.ErrDPoPProofReused:
+		return SCHEME_DPOP + ` error="invalid_dpop_proof", error_description="` + err.Error() + `",` + algs
+	case sessionModel.ErrTokenMalformed:
+		code, description = "invalid_request", "token malformed"
+	case sessionModel.ErrTokenExpired:
+		code, description = "invalid_token", "token expired"
+	case sessionModel.ErrTokenNotYetValid:
+		code, description = "invalid_token", "token not yet valid"
+	case sessionModel.ErrTokenSignature, sessionModel.ErrTokenAlgorithm, sessionModel.ErrTokenUnknownKey:
+		code, description = "invalid_token", "signature invalid"
+	case sessionModel.ErrTokenIssuer:
+		code, description = "invalid_token", "wrong issuer"
+	case sessionModel.ErrTokenAudience:
+		code, description = "invalid_token", "wrong audience"
+	case sessionModel.ErrTokenRevoked:
+		code, description = "invalid_token", "token revoked"
+	default:
+		code, description = "invalid_token", "token invalid"
+	}
+
+	return SCHEME_BEARER + ` realm="hysh", error="` + code + `", error_description="` + description + `"`
+}
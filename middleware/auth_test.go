@@ -0,0 +1,92 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/go-chi/chi"
+	goredis "github.com/go-redis/redis"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+	"github.com/handymesh/hyshAuthService/handlers/session"
+	"github.com/handymesh/hyshAuthService/handlers/user"
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+// newTestToken signs an access token of the claims with a key
+// the validator accepts for the duration of the test
+func newTestToken(t *testing.T, claims sessionModel.Claims) string {
+	key, err := sessionModel.NewSigningKey("ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFunc := sessionModel.AccessTokens.Key
+	sessionModel.AccessTokens.Key = func(kid string) *sessionModel.SigningKey {
+		if kid == key.Kid {
+			return key
+		}
+		return keyFunc(kid)
+	}
+	t.Cleanup(func() { sessionModel.AccessTokens.Key = keyFunc })
+
+	now := time.Now()
+	claims.Id = now.Format(time.RFC3339Nano)
+	claims.Issuer = sessionModel.ISSUER
+	claims.Audience = sessionModel.AUDIENCE
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(time.Minute).Unix()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.Kid
+	signed, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestRequireFirstParty(t *testing.T) {
+	server := miniredis.RunT(t)
+	redis.Redis = goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	defer redis.Redis.Close()
+
+	const userId = "5c3a1b2e9d4f6a7b8c9d0e1f"
+	r := chi.NewRouter()
+	r.Mount("/users", user.Routes())
+	r.Mount("/auth", session.Routes())
+
+	// A third-party client may act for its own user, but not manage the account
+	token := newTestToken(t, sessionModel.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: userId},
+		ClientID:       "third-party",
+	})
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPatch, "/users/" + userId},
+		{http.MethodDelete, "/users/" + userId},
+		{http.MethodGet, "/auth/sessions"},
+		{http.MethodDelete, "/auth/sessions"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(`{"fullname":"Name"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("[%s %s] got %v want %v", test.method, test.path, w.Code, http.StatusForbidden)
+		}
+	}
+}
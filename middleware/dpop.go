@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	sessionModel "github.com/handymesh/hyshAuthService/models/session"
+)
+
+type dpopCtxKey struct{}
+
+// DPoP verifies the DPoP proof sent to a token endpoint and puts the
+// thumbprint of its key into the request context, see GetDPoPThumbprint.
+// Requests without a proof pass unchanged and get bearer tokens.
+func DPoP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proof := r.Header.Get("DPoP")
+		if proof == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		jkt, err := sessionModel.VerifyDPoPProof(proof, r.Method, requestURI(r), "")
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":             "invalid_dpop_proof",
+				"error_description": err.Error(),
+			})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), dpopCtxKey{}, jkt)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetDPoPThumbprint returns the key thumbprint set by DPoP, or an empty string
+func GetDPoPThumbprint(ctx context.Context) string {
+	jkt, _ := ctx.Value(dpopCtxKey{}).(string)
+	return jkt
+}
+
+// verifyTokenBinding checks that a DPoP-bound access token comes with the DPoP
+// scheme and a proof of its key, and that a bearer token is sent as such
+func verifyTokenBinding(r *http.Request, scheme string, token string, claims *sessionModel.Claims) error {
+	jkt := claims.KeyThumbprint()
+	if jkt == "" {
+		if scheme == SCHEME_DPOP {
+			return ErrTokenNotBound
+		}
+		return nil
+	}
+
+	if scheme != SCHEME_DPOP {
+		return ErrTokenBound
+	}
+
+	proofJkt, err := sessionModel.VerifyDPoPProof(r.Header.Get("DPoP"), r.Method, requestURI(r), token)
+	if err != nil {
+		return err
+	}
+	if proofJkt != jkt {
+		return sessionModel.ErrDPoPProofInvalid
+	}
+
+	return nil
+}
+
+// requestURI is the URI the client sent the request to, behind the issuer URL
+func requestURI(r *http.Request) string {
+	return sessionModel.ISSUER + r.URL.Path
+}
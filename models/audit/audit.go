@@ -0,0 +1,65 @@
+package auditModel
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/handymesh/hyshAuthService/db/mongodb"
+)
+
+const (
+	// CollectionAudit holds the name of the audit log collection
+	CollectionAudit = "audit"
+
+	TYPE_TOKEN_EXCHANGE = "token_exchange"
+	TYPE_IMPERSONATION  = "impersonation"
+)
+
+// Add appends the record to the audit log
+func Add(record *Record) error {
+	record.CreatedAt = time.Now()
+
+	res, err := mongodb.Session.Database("auth").Collection(CollectionAudit).InsertOne(nil, record)
+	if err != nil {
+		return err
+	}
+
+	record.Id = res.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// List returns the newest records about the subject, or all subjects if empty
+func List(subjectId string, limit int64) ([]Record, error) {
+	filter := bson.M{}
+	if subjectId != "" {
+		filter["subject_id"] = subjectId
+	}
+
+	records := []Record{}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	cursor, err := mongodb.Session.Database("auth").Collection(CollectionAudit).Find(nil, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(nil)
+
+	if err = cursor.All(nil, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// EnsureIndexes creates the indexes of the audit log queries
+func EnsureIndexes() error {
+	_, err := mongodb.Session.Database("auth").Collection(CollectionAudit).Indexes().CreateMany(nil, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subject_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	})
+	return err
+}
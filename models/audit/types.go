@@ -0,0 +1,16 @@
+package auditModel
+
+import "time"
+
+// Record is an audited action taken with delegated authority
+type Record struct {
+	Id        string    `json:"id" bson:"_id,omitempty"`
+	Type      string    `json:"type" bson:"type"`
+	ActorID   string    `json:"actor_id" bson:"actor_id"`
+	SubjectID string    `json:"subject_id" bson:"subject_id"`
+	ClientID  string    `json:"client_id" bson:"client_id"`
+	Scope     string    `json:"scope,omitempty" bson:"scope,omitempty"`
+	TokenID   string    `json:"token_id,omitempty" bson:"token_id,omitempty"`
+	IP        string    `json:"ip,omitempty" bson:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,97 @@
+package clientModel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+	"github.com/handymesh/hyshAuthService/utils/jose"
+)
+
+const (
+	// CLIENT_ASSERTION_TYPE_JWT is the client_assertion_type of private_key_jwt (RFC 7523)
+	CLIENT_ASSERTION_TYPE_JWT = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	// maximal lifetime of a client assertion
+	CLIENT_ASSERTION_MAX_AGE = time.Minute * 5
+
+	CLIENT_ASSERTION_JTI_PREFIX = "jti:client_assertion:"
+)
+
+// signing algorithm expected for each key type
+var assertionAlgs = map[string]string{
+	"RSA": "RS256",
+	"EC":  "ES256",
+	"OKP": "EdDSA",
+}
+
+// assertionClaims of a client assertion, aud may be a string or an array
+type assertionClaims struct {
+	Issuer    string        `json:"iss"`
+	Subject   string        `json:"sub"`
+	Audience  jose.Audience `json:"aud"`
+	ExpiresAt int64         `json:"exp"`
+	IssuedAt  int64         `json:"iat,omitempty"`
+	Id        string        `json:"jti"`
+}
+
+func (c *assertionClaims) Valid() error {
+	now := time.Now()
+	if c.ExpiresAt == 0 || now.Unix() > c.ExpiresAt {
+		return errors.New("client assertion expired")
+	}
+	if time.Unix(c.ExpiresAt, 0).Sub(now) > CLIENT_ASSERTION_MAX_AGE {
+		return errors.New("client assertion lifetime is too long")
+	}
+	return nil
+}
+
+// AuthenticateAssertion checks a private_key_jwt client assertion signed with
+// a key of the client's JWKS. The assertion must name one of the audiences
+// and can be used only once.
+func AuthenticateAssertion(assertion string, audiences ...string) (*Client, error) {
+	var client *Client
+	claims := &assertionClaims{}
+
+	_, err := jwt.ParseWithClaims(assertion, claims, func(token *jwt.Token) (interface{}, error) {
+		// The client is identified by the unverified issuer
+		var err error
+		client, err = FindByID(claims.Issuer)
+		if err != nil || client.JWKS == nil {
+			return nil, ErrInvalidClient
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		jwk, ok := client.JWKS.Key(kid)
+		if !ok {
+			return nil, errors.New("unknown client key")
+		}
+
+		if token.Method.Alg() != assertionAlgs[jwk.Kty] || (jwk.Alg != "" && jwk.Alg != token.Method.Alg()) {
+			return nil, errors.New("unexpected signing method")
+		}
+
+		return jwk.PublicKey()
+	})
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+
+	if claims.Subject != claims.Issuer || claims.Id == "" || !claims.Audience.Contains(audiences...) {
+		return nil, ErrInvalidClient
+	}
+
+	// Replay protection until the assertion expires
+	ttl := time.Until(time.Unix(claims.ExpiresAt, 0)) + time.Second
+	fresh, err := redis.Redis.SetNX(CLIENT_ASSERTION_JTI_PREFIX+client.Id+":"+claims.Id, "true", ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, ErrInvalidClient
+	}
+
+	return client, nil
+}
@@ -0,0 +1,280 @@
+package clientModel
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/handymesh/hyshAuthService/db/mongodb"
+	"github.com/handymesh/hyshAuthService/utils"
+	"github.com/handymesh/hyshAuthService/utils/crypto"
+)
+
+const (
+	// CollectionClient holds the name of the OAuth clients collection
+	CollectionClient = "clients"
+
+	GRANT_AUTHORIZATION_CODE = "authorization_code"
+	GRANT_REFRESH_TOKEN      = "refresh_token"
+	GRANT_CLIENT_CREDENTIALS = "client_credentials"
+	GRANT_DEVICE_CODE        = "urn:ietf:params:oauth:grant-type:device_code"
+	GRANT_TOKEN_EXCHANGE     = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	AUTH_METHOD_SECRET_BASIC    = "client_secret_basic"
+	AUTH_METHOD_SECRET_POST     = "client_secret_post"
+	AUTH_METHOD_PRIVATE_KEY_JWT = "private_key_jwt"
+	AUTH_METHOD_NONE            = "none"
+)
+
+var (
+	// Get configuration
+	CLIENT_SECRET_GRACE_PERIOD = utils.Getenv("CLIENT_SECRET_GRACE_PERIOD", "24h")
+
+	// GrantTypes the auth service supports
+	GrantTypes = map[string]bool{
+		GRANT_AUTHORIZATION_CODE: true,
+		GRANT_REFRESH_TOKEN:      true,
+		GRANT_CLIENT_CREDENTIALS: true,
+		GRANT_DEVICE_CODE:        true,
+		GRANT_TOKEN_EXCHANGE:     true,
+	}
+
+	ErrInvalidClient      = errors.New("invalid client")
+	ErrClientNotFound     = errors.New("client not found")
+	ErrInvalidRedirectURI = errors.New("invalid redirect URI")
+)
+
+func FindByID(clientId string) (*Client, error) {
+	var result *Client
+	err := clientsCollection().FindOne(nil, bson.M{"_id": clientId}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrClientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func List() ([]Client, error) {
+	clients := []Client{}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := clientsCollection().Find(nil, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(nil)
+
+	if err = cursor.All(nil, &clients); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+// Add registers the client and returns its secret, which is
+// only stored as a hash. Clients without a secret get an empty one.
+func Add(client *Client) (string, error) {
+	if client.TokenEndpointAuthMethod == "" {
+		client.TokenEndpointAuthMethod = AUTH_METHOD_SECRET_BASIC
+	}
+	if err := client.Validate(); err != nil {
+		return "", err
+	}
+
+	if client.Id == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return "", err
+		}
+		client.Id = id.String()
+	}
+
+	var secret string
+	client.SecretHash = ""
+	if client.UsesSecret() {
+		var err error
+		secret, client.SecretHash, err = newSecret()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	now := time.Now()
+	client.CreatedAt = &now
+	client.UpdatedAt = &now
+
+	_, err := clientsCollection().InsertOne(nil, client)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", errors.New("client " + client.Id + " already exists")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// Update replaces the client metadata. A client switching to secret
+// authentication gets a new secret, which is returned.
+func Update(client *Client) (string, error) {
+	current, err := FindByID(client.Id)
+	if err != nil {
+		return "", err
+	}
+
+	if client.TokenEndpointAuthMethod == "" {
+		client.TokenEndpointAuthMethod = current.TokenEndpointAuthMethod
+	}
+	if err := client.Validate(); err != nil {
+		return "", err
+	}
+
+	var secret string
+	client.SecretHash = ""
+	client.PreviousSecretHash = ""
+	client.PreviousSecretExpiresAt = nil
+	if client.UsesSecret() {
+		client.SecretHash = current.SecretHash
+		client.PreviousSecretHash = current.PreviousSecretHash
+		client.PreviousSecretExpiresAt = current.PreviousSecretExpiresAt
+		if client.SecretHash == "" {
+			secret, client.SecretHash, err = newSecret()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	now := time.Now()
+	client.RegistrationTokenHash = current.RegistrationTokenHash
+	client.CreatedAt = current.CreatedAt
+	client.UpdatedAt = &now
+
+	result, err := clientsCollection().ReplaceOne(nil, bson.M{"_id": client.Id}, client)
+	if err != nil {
+		return "", err
+	}
+	if result.MatchedCount == 0 {
+		return "", ErrClientNotFound
+	}
+
+	return secret, nil
+}
+
+func Delete(clientId string) (int64, error) {
+	res, err := clientsCollection().DeleteOne(nil, bson.M{"_id": clientId})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
+
+// RotateSecret issues a new client secret. The previous secret stays
+// valid for CLIENT_SECRET_GRACE_PERIOD so the client can be redeployed.
+func RotateSecret(clientId string) (string, error) {
+	client, err := FindByID(clientId)
+	if err != nil {
+		return "", err
+	}
+	if !client.UsesSecret() {
+		return "", errors.New("client does not authenticate with a secret")
+	}
+
+	gracePeriod, err := time.ParseDuration(CLIENT_SECRET_GRACE_PERIOD)
+	if err != nil {
+		return "", err
+	}
+
+	secret, hash, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(gracePeriod)
+	_, err = clientsCollection().UpdateOne(nil, bson.M{"_id": clientId}, bson.M{"$set": bson.M{
+		"secret_hash":                hash,
+		"previous_secret_hash":       client.SecretHash,
+		"previous_secret_expires_at": expiresAt,
+		"updated_at":                 now,
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// Authenticate checks the credentials of a confidential client
+func Authenticate(clientId string, secret string) (*Client, error) {
+	if clientId == "" || secret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := FindByID(clientId)
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+
+	if !client.UsesSecret() || client.SecretHash == "" {
+		return nil, ErrInvalidClient
+	}
+
+	if secretMatches(client.Id, secret, client.SecretHash, "secret_hash") {
+		return client, nil
+	}
+
+	// The secret replaced by the last rotation
+	if client.PreviousSecretHash != "" && client.PreviousSecretExpiresAt != nil &&
+		time.Now().Before(*client.PreviousSecretExpiresAt) &&
+		secretMatches(client.Id, secret, client.PreviousSecretHash, "previous_secret_hash") {
+		return client, nil
+	}
+
+	return nil, ErrInvalidClient
+}
+
+// secretMatches compares the secret with its stored hash. The secret is
+// random, a fast hash is enough. A bcrypt hash of a secret issued before
+// is replaced on its first use, if that fails it is checked again next time.
+func secretMatches(clientId string, secret string, hash string, field string) bool {
+	if !strings.HasPrefix(hash, "$2") {
+		return tokenMatches(secret, hash)
+	}
+
+	if !crypto.CheckPasswordHash(secret, hash) {
+		return false
+	}
+
+	clientsCollection().UpdateOne(nil,
+		bson.M{"_id": clientId, field: hash},
+		bson.M{"$set": bson.M{field: hashToken(secret)}},
+	)
+	return true
+}
+
+// newSecret returns a random client secret and its hash
+func newSecret() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	return secret, hashToken(secret), nil
+}
+
+func clientsCollection() *mongo.Collection {
+	return mongodb.Session.Database("auth").Collection(CollectionClient)
+}
@@ -0,0 +1,63 @@
+package clientModel
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// NewRegistrationToken issues the registration access token of a dynamically
+// registered client, replacing the previous one. Only its hash is stored.
+func NewRegistrationToken(clientId string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	result, err := clientsCollection().UpdateOne(nil,
+		bson.M{"_id": clientId},
+		bson.M{"$set": bson.M{"registration_token_hash": hashToken(token)}},
+	)
+	if err != nil {
+		return "", err
+	}
+	if result.MatchedCount == 0 {
+		return "", ErrClientNotFound
+	}
+
+	return token, nil
+}
+
+// AuthenticateRegistration checks the registration access token of the client
+func AuthenticateRegistration(clientId string, token string) (*Client, error) {
+	if clientId == "" || token == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := FindByID(clientId)
+	if err != nil || client.RegistrationTokenHash == "" {
+		return nil, ErrInvalidClient
+	}
+
+	// The token is random, a fast hash is enough
+	if !tokenMatches(token, client.RegistrationTokenHash) {
+		return nil, ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// hashToken hashes a random token or client secret for storage
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func tokenMatches(token string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(hash)) == 1
+}
@@ -0,0 +1,182 @@
+package clientModel
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/handymesh/hyshAuthService/utils/jose"
+)
+
+// Client is an OAuth client registered with the auth service
+type Client struct {
+	Id                      string     `json:"client_id" bson:"_id"`
+	SecretHash              string     `json:"-" bson:"secret_hash,omitempty"`
+	PreviousSecretHash      string     `json:"-" bson:"previous_secret_hash,omitempty"`
+	PreviousSecretExpiresAt *time.Time `json:"-" bson:"previous_secret_expires_at,omitempty"`
+	TokenEndpointAuthMethod string     `json:"token_endpoint_auth_method" bson:"token_endpoint_auth_method,omitempty"`
+	JWKS                    *jose.JWKS `json:"jwks,omitempty" bson:"jwks,omitempty"`
+
+	Name         string   `json:"client_name" bson:"name,omitempty"`
+	RedirectURIs []string `json:"redirect_uris" bson:"redirect_uris,omitempty"`
+	GrantTypes   []string `json:"grant_types" bson:"grant_types,omitempty"`
+	Scopes       []string `json:"scopes" bson:"scopes,omitempty"`
+
+	// Set for dynamically registered clients, see RFC 7592
+	RegistrationTokenHash string `json:"-" bson:"registration_token_hash,omitempty"`
+
+	// Token lifetimes in seconds, 0 uses the default of the auth service.
+	// RefreshTokenLifetime is the absolute lifetime of a session,
+	// a recovery link can only be shortened.
+	AccessTokenLifetime      int64 `json:"access_token_lifetime,omitempty" bson:"access_token_lifetime,omitempty"`
+	IDTokenLifetime          int64 `json:"id_token_lifetime,omitempty" bson:"id_token_lifetime,omitempty"`
+	RefreshTokenIdleLifetime int64 `json:"refresh_token_idle_lifetime,omitempty" bson:"refresh_token_idle_lifetime,omitempty"`
+	RefreshTokenLifetime     int64 `json:"refresh_token_lifetime,omitempty" bson:"refresh_token_lifetime,omitempty"`
+	RecoveryLinkLifetime     int64 `json:"recovery_link_lifetime,omitempty" bson:"recovery_link_lifetime,omitempty"`
+
+	CreatedAt *time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+}
+
+// IsConfidential reports whether the client authenticates with
+// a secret or with a private_key_jwt assertion
+func (c *Client) IsConfidential() bool {
+	return c.SecretHash != "" || (c.JWKS != nil && len(c.JWKS.Keys) > 0)
+}
+
+// UsesSecret reports whether the client authenticates with a client secret
+func (c *Client) UsesSecret() bool {
+	switch c.TokenEndpointAuthMethod {
+	case AUTH_METHOD_SECRET_BASIC, AUTH_METHOD_SECRET_POST:
+		return true
+	case "":
+		// Clients registered before the method was stored
+		return c.SecretHash != ""
+	}
+	return false
+}
+
+// HasRedirectURI checks the redirect URI by exact string match
+func (c *Client) HasRedirectURI(uri string) bool {
+	for _, u := range c.RedirectURIs {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsGrant reports whether the client may use the grant type
+func (c *Client) AllowsGrant(grantType string) bool {
+	for _, g := range c.GrantTypes {
+		if g == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedScope narrows the requested scope to the scopes of the client.
+// An empty request grants every scope of the client.
+func (c *Client) AllowedScope(requested string) string {
+	if requested == "" {
+		return strings.Join(c.Scopes, " ")
+	}
+
+	allowed := []string{}
+	for _, scope := range strings.Fields(requested) {
+		for _, s := range c.Scopes {
+			if s == scope {
+				allowed = append(allowed, scope)
+				break
+			}
+		}
+	}
+
+	return strings.Join(allowed, " ")
+}
+
+// Schemes the browser runs or reads locally instead of navigating to the client
+var unsafeRedirectSchemes = map[string]bool{
+	"javascript": true,
+	"vbscript":   true,
+	"data":       true,
+	"blob":       true,
+	"file":       true,
+}
+
+// RegistrableRedirectURI reports whether a client may register the redirect URI
+// by itself: https, http on the loopback interface or a private-use scheme
+// named after a reverse domain name (RFC 8252, sections 7.1 and 7.3)
+func RegistrableRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Fragment != "" {
+		return false
+	}
+
+	switch scheme := strings.ToLower(u.Scheme); scheme {
+	case "https":
+		return u.Host != ""
+	case "http":
+		switch u.Hostname() {
+		case "127.0.0.1", "::1", "localhost":
+			return true
+		}
+		return false
+	default:
+		return strings.Contains(scheme, ".") && !unsafeRedirectSchemes[scheme]
+	}
+}
+
+// Validate checks the client metadata set by an operator or the client itself
+func (c *Client) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("client_name is required")
+	}
+
+	for _, grantType := range c.GrantTypes {
+		if !GrantTypes[grantType] {
+			return errors.New("unsupported grant type " + grantType)
+		}
+	}
+
+	if c.AllowsGrant(GRANT_AUTHORIZATION_CODE) && len(c.RedirectURIs) == 0 {
+		return fmt.Errorf("%w: redirect_uris are required for the authorization_code grant", ErrInvalidRedirectURI)
+	}
+	for _, uri := range c.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || !u.IsAbs() || u.Fragment != "" {
+			return fmt.Errorf("%w: %s must be absolute and without fragment", ErrInvalidRedirectURI, uri)
+		}
+		if unsafeRedirectSchemes[strings.ToLower(u.Scheme)] {
+			return fmt.Errorf("%w: %s scheme is not allowed", ErrInvalidRedirectURI, u.Scheme)
+		}
+	}
+
+	switch c.TokenEndpointAuthMethod {
+	case AUTH_METHOD_SECRET_BASIC, AUTH_METHOD_SECRET_POST:
+	case AUTH_METHOD_PRIVATE_KEY_JWT:
+		if c.JWKS == nil || len(c.JWKS.Keys) == 0 {
+			return errors.New("jwks is required for private_key_jwt")
+		}
+		for _, key := range c.JWKS.Keys {
+			if _, err := key.PublicKey(); err != nil {
+				return errors.New("jwks: " + err.Error())
+			}
+		}
+	case AUTH_METHOD_NONE:
+		if c.AllowsGrant(GRANT_CLIENT_CREDENTIALS) {
+			return errors.New("public clients may not use the client_credentials grant")
+		}
+	default:
+		return errors.New("unsupported token_endpoint_auth_method " + c.TokenEndpointAuthMethod)
+	}
+
+	if c.AccessTokenLifetime < 0 || c.IDTokenLifetime < 0 || c.RefreshTokenIdleLifetime < 0 || c.RefreshTokenLifetime < 0 || c.RecoveryLinkLifetime < 0 {
+		return errors.New("token lifetimes must not be negative")
+	}
+
+	return nil
+}
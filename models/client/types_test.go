@@ -0,0 +1,64 @@
+package clientModel
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistrableRedirectURI(t *testing.T) {
+	tests := []struct {
+		uri   string
+		valid bool
+	}{
+		{"https://app.hysh.test/callback", true},
+		{"http://127.0.0.1:51004/callback", true},
+		{"http://[::1]:51004/callback", true},
+		{"http://localhost:3000/callback", true},
+		{"com.hysh.app:/callback", true},
+		{"http://app.hysh.test/callback", false},
+		{"https:///callback", false},
+		{"https://app.hysh.test/callback#fragment", false},
+		{"/callback", false},
+		{"myapp:/callback", false},
+		{"javascript:alert(1)", false},
+		{"data:text/html,<script>alert(1)</script>", false},
+		{"file:///etc/passwd", false},
+	}
+
+	for _, test := range tests {
+		if valid := RegistrableRedirectURI(test.uri); valid != test.valid {
+			t.Errorf("[%s] got %v want %v", test.uri, valid, test.valid)
+		}
+	}
+}
+
+func TestValidateRedirectURIs(t *testing.T) {
+	tests := []struct {
+		uri   string
+		valid bool
+	}{
+		{"https://app.hysh.test/callback", true},
+		{"http://app.hysh.test/callback", true},
+		{"javascript:alert(1)", false},
+		{"JavaScript:alert(1)", false},
+		{"data:text/html,<script>alert(1)</script>", false},
+		{"file:///etc/passwd", false},
+	}
+
+	for _, test := range tests {
+		client := &Client{
+			Name:                    "Client",
+			RedirectURIs:            []string{test.uri},
+			GrantTypes:              []string{GRANT_AUTHORIZATION_CODE},
+			TokenEndpointAuthMethod: AUTH_METHOD_NONE,
+		}
+
+		err := client.Validate()
+		if (err == nil) != test.valid {
+			t.Errorf("[%s] got %v want valid %v", test.uri, err, test.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidRedirectURI) {
+			t.Errorf("[%s] got %v want %v", test.uri, err, ErrInvalidRedirectURI)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package outboxModel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/handymesh/hyshAuthService/db/mongodb"
+)
+
+const (
+	// CollectionOutbox holds the name of the mail outbox collection
+	CollectionOutbox = "outbox"
+
+	STATUS_PENDING = "pending"
+	STATUS_SENDING = "sending"
+	STATUS_SENT    = "sent"
+	STATUS_DEAD    = "dead"
+)
+
+var (
+	ErrMessageNotFound = errors.New("outbox message not found")
+	ErrClaimLost       = errors.New("outbox message claimed by another worker")
+)
+
+// Add enqueues the message for delivery, a new id is generated if it has none.
+// It returns false when a message with the same id is already enqueued.
+func Add(message *Message) (bool, error) {
+	if message.Id == "" {
+		message.Id = uuid.New().String()
+	}
+
+	now := time.Now()
+	message.Status = STATUS_PENDING
+	message.Attempts = 0
+	message.NextAttemptAt = now
+	message.CreatedAt = now
+	message.UpdatedAt = now
+
+	_, err := outboxCollection().InsertOne(nil, message)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Claim takes the due message of the highest priority for a delivery attempt. The message is
+// locked for the lease, a message of a crashed worker is claimed again once
+// its lease expires. It returns nil when no message is due.
+func Claim(lease time.Duration) (*Message, error) {
+	now := time.Now()
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": STATUS_PENDING, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"status": STATUS_SENDING, "locked_until": bson.M{"$lte": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": STATUS_SENDING, "locked_until": now.Add(lease), "claim_token": uuid.New().String(), "updated_at": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var message Message
+	err := outboxCollection().FindOneAndUpdate(nil, filter, update, opts).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// MarkSent records the delivery. The URL is dropped, it may carry a secret.
+func MarkSent(id string, claimToken string) error {
+	now := time.Now()
+	return updateSending(id, claimToken, bson.M{
+		"$set":   bson.M{"status": STATUS_SENT, "sent_at": now, "updated_at": now},
+		"$unset": bson.M{"encrypted_url": "", "url": "", "last_error": "", "claim_token": ""},
+	})
+}
+
+// Retry schedules the next delivery attempt of a failed message
+func Retry(id string, claimToken string, nextAttemptAt time.Time, lastError string) error {
+	return updateSending(id, claimToken, bson.M{
+		"$set":   bson.M{"status": STATUS_PENDING, "next_attempt_at": nextAttemptAt, "last_error": lastError, "updated_at": time.Now()},
+		"$unset": bson.M{"claim_token": ""},
+	})
+}
+
+// MarkDead moves a message that can't be delivered to the dead letters
+func MarkDead(id string, claimToken string, lastError string) error {
+	return updateSending(id, claimToken, bson.M{
+		"$set":   bson.M{"status": STATUS_DEAD, "last_error": lastError, "updated_at": time.Now()},
+		"$unset": bson.M{"claim_token": ""},
+	})
+}
+
+// Requeue schedules a dead letter for delivery again with fresh attempts
+func Requeue(id string) error {
+	now := time.Now()
+	res, err := outboxCollection().UpdateOne(nil,
+		bson.M{"_id": id, "status": STATUS_DEAD},
+		bson.M{"$set": bson.M{"status": STATUS_PENDING, "attempts": 0, "next_attempt_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrMessageNotFound
+	}
+
+	return nil
+}
+
+// GetStats counts the messages by status
+func GetStats() (*Stats, error) {
+	stats := &Stats{}
+	for status, count := range map[string]*int64{
+		STATUS_PENDING: &stats.Pending,
+		STATUS_SENDING: &stats.Sending,
+		STATUS_SENT:    &stats.Sent,
+		STATUS_DEAD:    &stats.Dead,
+	} {
+		n, err := outboxCollection().CountDocuments(nil, bson.M{"status": status})
+		if err != nil {
+			return nil, err
+		}
+		*count = n
+	}
+
+	var oldest Message
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	err := outboxCollection().FindOne(nil, bson.M{"status": STATUS_PENDING}, opts).Decode(&oldest)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if err == nil {
+		stats.OldestPendingAt = &oldest.CreatedAt
+	}
+
+	return stats, nil
+}
+
+// List returns the newest messages, of one status if not empty
+func List(status string, limit int64) ([]Message, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	messages := []Message{}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	cursor, err := outboxCollection().Find(nil, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(nil)
+
+	if err = cursor.All(nil, &messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// Purge removes the messages sent before the given time
+func Purge(before time.Time) (int64, error) {
+	res, err := outboxCollection().DeleteMany(nil, bson.M{"status": STATUS_SENT, "sent_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
+
+// updateSending updates a message only while it is claimed for delivery by the
+// same claim. A worker whose lease expired must not overwrite the outcome of
+// the worker which claimed the message again.
+func updateSending(id string, claimToken string, update bson.M) error {
+	filter := bson.M{"_id": id, "status": STATUS_SENDING, "claim_token": claimToken}
+	res, err := outboxCollection().UpdateOne(nil, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// EnsureIndexes creates the indexes of the delivery queue and the admin queries
+func EnsureIndexes() error {
+	_, err := outboxCollection().Indexes().CreateMany(nil, []mongo.IndexModel{
+		// Claim of due messages by priority
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "priority", Value: -1}, {Key: "next_attempt_at", Value: 1}}},
+		// Claim of messages with an expired lease
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "locked_until", Value: 1}}},
+		// List and the oldest pending message
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		// Purge
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "sent_at", Value: 1}}},
+	})
+	return err
+}
+
+func outboxCollection() *mongo.Collection {
+	return mongodb.Session.Database("auth").Collection(CollectionOutbox)
+}
@@ -0,0 +1,50 @@
+package outboxModel
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/handymesh/hyshAuthService/db/mongodb"
+)
+
+func TestUpdateSendingRequiresClaim(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("claim", func(mt *mtest.T) {
+		session := mongodb.Session
+		mongodb.Session = mt.Client
+		defer func() { mongodb.Session = session }()
+
+		tests := []struct {
+			name    string
+			matched int32
+			err     error
+		}{
+			{"current claim", 1, nil},
+			{"expired claim", 0, ErrClaimLost},
+		}
+
+		for _, test := range tests {
+			mt.ClearEvents()
+			mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: test.matched}, {Key: "nModified", Value: test.matched}})
+
+			err := MarkSent("message", "claim")
+			if err != test.err {
+				t.Errorf("[%s] got %v want %v", test.name, err, test.err)
+			}
+
+			// The update only applies to the message of this claim
+			started := mt.GetStartedEvent()
+			if started == nil {
+				t.Fatalf("[%s] no update sent", test.name)
+			}
+			filter := started.Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+			if token, ok := filter.Lookup("claim_token").StringValueOK(); !ok || token != "claim" {
+				t.Errorf("[%s] got filter %v", test.name, filter)
+			}
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package outboxModel
+
+import (
+	"time"
+)
+
+// Message is a mail waiting in the outbox. Its id is the idempotency key,
+// a message enqueued twice with the same key is only sent once.
+type Message struct {
+	Id            string            `json:"id" bson:"_id"`
+	Template      string            `json:"template" bson:"template"`
+	To            string            `json:"to" bson:"to"`
+	EncryptedURL  string            `json:"-" bson:"encrypted_url,omitempty"`
+	Locale        string            `json:"locale,omitempty" bson:"locale,omitempty"`
+	Variables     map[string]string `json:"variables,omitempty" bson:"variables,omitempty"`
+	RequestID     string            `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	Priority      int               `json:"priority" bson:"priority"`
+	Status        string            `json:"status" bson:"status"`
+	Attempts      int               `json:"attempts" bson:"attempts"`
+	LastError     string            `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	NextAttemptAt time.Time         `json:"next_attempt_at" bson:"next_attempt_at"`
+	LockedUntil   time.Time         `json:"-" bson:"locked_until"`
+	CreatedAt     time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at" bson:"updated_at"`
+	SentAt        *time.Time        `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+	// Set by each claim, only the worker holding it may update the message
+	ClaimToken string `json:"-" bson:"claim_token,omitempty"`
+	// Tracing context of the request which enqueued the message
+	Trace map[string]string `json:"-" bson:"trace,omitempty"`
+	// Plaintext URL of a message enqueued before URLs were encrypted
+	URL string `json:"-" bson:"url,omitempty"`
+}
+
+// Stats is the number of messages by status
+type Stats struct {
+	Pending         int64      `json:"pending"`
+	Sending         int64      `json:"sending"`
+	Sent            int64      `json:"sent"`
+	Dead            int64      `json:"dead"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+}
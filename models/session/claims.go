@@ -0,0 +1,117 @@
+package sessionModel
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	SUBJECT_TYPE_USER   = "user"
+	SUBJECT_TYPE_CLIENT = "client"
+)
+
+// Claims of an access token. Tokens of the client credentials grant
+// have the client as subject and sub_type "client".
+type Claims struct {
+	jwt.StandardClaims
+	SubjectType string   `json:"sub_type,omitempty"`
+	SessionID   string   `json:"sid,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	Act         *Actor   `json:"act,omitempty"`
+	// Set for tokens bound to a DPoP key
+	Cnf *Confirmation `json:"cnf,omitempty"`
+}
+
+// Actor is the party acting on behalf of the subject of a token obtained
+// by token exchange. Act holds the previous actor of a chain of exchanges.
+type Actor struct {
+	Subject     string `json:"sub"`
+	SubjectType string `json:"sub_type,omitempty"`
+	Act         *Actor `json:"act,omitempty"`
+}
+
+// Principal is the caller identified by an access token
+type Principal struct {
+	Subject     string
+	SubjectType string
+	SessionID   string
+	ClientID    string
+	Roles       []string
+	Scopes      []string
+	TokenID     string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	Actor       *Actor
+}
+
+// Principal builds the caller identity from verified claims.
+// Roles are only honoured in tokens of first-party clients.
+func (c *Claims) Principal() *Principal {
+	subjectType := c.SubjectType
+	if subjectType == "" {
+		subjectType = SUBJECT_TYPE_USER
+	}
+
+	var roles []string
+	if IsFirstParty(c.ClientID) {
+		roles = c.Roles
+	}
+
+	return &Principal{
+		Subject:     c.Subject,
+		SubjectType: subjectType,
+		SessionID:   c.SessionID,
+		ClientID:    c.ClientID,
+		Roles:       roles,
+		Scopes:      strings.Fields(c.Scope),
+		TokenID:     c.Id,
+		IssuedAt:    time.Unix(c.IssuedAt, 0),
+		ExpiresAt:   time.Unix(c.ExpiresAt, 0),
+		Actor:       c.Act,
+	}
+}
+
+// Scope returns the granted scopes as a space separated string
+func (p *Principal) Scope() string {
+	return strings.Join(p.Scopes, " ")
+}
+
+// IsClient reports whether the caller is a service authenticated with its client credentials
+func (p *Principal) IsClient() bool {
+	return p.SubjectType == SUBJECT_TYPE_CLIENT
+}
+
+// IsFirstPartyUser reports whether the caller is a user signed in to a first-party client
+func (p *Principal) IsFirstPartyUser() bool {
+	return !p.IsClient() && IsFirstParty(p.ClientID)
+}
+
+// KeyThumbprint returns the thumbprint of the DPoP key the token is bound to, if any
+func (c *Claims) KeyThumbprint() string {
+	if c.Cnf == nil {
+		return ""
+	}
+	return c.Cnf.JKT
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
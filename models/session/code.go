@@ -0,0 +1,50 @@
+package sessionModel
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+)
+
+const CODE_CHALLENGE_S256 = "S256"
+
+var ErrAuthorizationCodeInvalid = errors.New("authorization code invalid")
+
+// NewAuthorizationCode issues a short-lived single-use authorization code
+func NewAuthorizationCode(code AuthorizationCode) (string, error) {
+	token, err := NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = Tokens.Put(PurposeAuthorization, token, code, AUTHORIZATION_CODE_DURATION)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumeAuthorizationCode returns the code record, the code can not be used again
+func ConsumeAuthorizationCode(token string) (*AuthorizationCode, error) {
+	var code AuthorizationCode
+	err := Tokens.Consume(PurposeAuthorization, token, &code)
+	if err != nil {
+		return nil, ErrAuthorizationCodeInvalid
+	}
+
+	return &code, nil
+}
+
+// VerifyCodeVerifier checks the PKCE code verifier against the S256 challenge (RFC 7636)
+func (c *AuthorizationCode) VerifyCodeVerifier(verifier string) bool {
+	if c.CodeChallengeMethod != CODE_CHALLENGE_S256 || len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(c.CodeChallenge)) == 1
+}
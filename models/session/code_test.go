@@ -0,0 +1,53 @@
+package sessionModel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyCodeVerifier(t *testing.T) {
+	// Example of RFC 7636, appendix B
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	const challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	tests := []struct {
+		name     string
+		code     AuthorizationCode
+		verifier string
+		valid    bool
+	}{
+		{"matching verifier", AuthorizationCode{CodeChallenge: challenge, CodeChallengeMethod: CODE_CHALLENGE_S256}, verifier, true},
+		{"other verifier", AuthorizationCode{CodeChallenge: challenge, CodeChallengeMethod: CODE_CHALLENGE_S256}, strings.Repeat("a", 43), false},
+		{"challenge as verifier", AuthorizationCode{CodeChallenge: challenge, CodeChallengeMethod: CODE_CHALLENGE_S256}, challenge, false},
+		{"empty verifier", AuthorizationCode{CodeChallenge: challenge, CodeChallengeMethod: CODE_CHALLENGE_S256}, "", false},
+		{"too short verifier", AuthorizationCode{CodeChallenge: challenge, CodeChallengeMethod: CODE_CHALLENGE_S256}, verifier[:42], false},
+		{"too long verifier", AuthorizationCode{CodeChallenge: challenge, CodeChallengeMethod: CODE_CHALLENGE_S256}, strings.Repeat("a", 129), false},
+		{"plain method", AuthorizationCode{CodeChallenge: verifier, CodeChallengeMethod: "plain"}, verifier, false},
+		{"without challenge", AuthorizationCode{}, verifier, false},
+	}
+
+	for _, test := range tests {
+		if valid := test.code.VerifyCodeVerifier(test.verifier); valid != test.valid {
+			t.Errorf("[%s] got %v want %v", test.name, valid, test.valid)
+		}
+	}
+}
+
+func TestConsumeAuthorizationCode(t *testing.T) {
+	newTestRedis(t)
+
+	token, err := NewAuthorizationCode(AuthorizationCode{ClientID: "client", UserID: "5c3a1b2e9d4f6a7b8c9d0e1f"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := ConsumeAuthorizationCode(token)
+	if err != nil || code.ClientID != "client" {
+		t.Fatalf("first use: got %v, %v", code, err)
+	}
+
+	_, err = ConsumeAuthorizationCode(token)
+	if err != ErrAuthorizationCodeInvalid {
+		t.Errorf("second use: got %v want %v", err, ErrAuthorizationCodeInvalid)
+	}
+}
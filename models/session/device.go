@@ -0,0 +1,231 @@
+package sessionModel
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	DEVICE_STATUS_PENDING  = "pending"
+	DEVICE_STATUS_APPROVED = "approved"
+	DEVICE_STATUS_DENIED   = "denied"
+
+	// User codes avoid vowels and look-alike characters (RFC 8628, section 6.1)
+	USER_CODE_CHARSET = "BCDFGHJKLMNPQRSTVWXZ"
+	USER_CODE_LENGTH  = 8
+
+	// expired requests are kept a while to answer expired_token
+	DEVICE_CODE_RETENTION = time.Minute * 5
+
+	// slow_down raises the polling interval (RFC 8628, section 3.5)
+	DEVICE_SLOW_DOWN_INCREMENT = time.Second * 5
+
+	// User code entries allowed per window, for a session and from an IP
+	USER_CODE_ENTRIES_PER_SESSION = 10
+	USER_CODE_ENTRIES_PER_IP      = 30
+	USER_CODE_ENTRY_WINDOW        = time.Minute * 15
+)
+
+var (
+	ErrDeviceCodeInvalid = errors.New("device code invalid")
+	ErrDeviceCodeExpired = errors.New("device code expired")
+	ErrUserCodeInvalid   = errors.New("user code invalid")
+	ErrDeviceSlowDown    = errors.New("device polls too fast")
+	ErrUserCodeEntries   = errors.New("too many user code entries")
+)
+
+// NewDeviceAuthorization starts a device authorization request and
+// returns its device code and the user code to enter on another device
+func NewDeviceAuthorization(clientId string, scope string) (string, *DeviceAuthorization, error) {
+	deviceCode, err := NewOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	userCode, err := newUserCode()
+	if err != nil {
+		return "", nil, err
+	}
+
+	request := &DeviceAuthorization{
+		ClientID:  clientId,
+		Scope:     scope,
+		UserCode:  userCode,
+		Status:    DEVICE_STATUS_PENDING,
+		ExpiresAt: time.Now().Add(DEVICE_CODE_DURATION),
+		Interval:  DEVICE_POLLING_INTERVAL,
+	}
+
+	// A user code collision is unlikely but must not link two devices
+	created, err := Tokens.PutNX(PurposeUserCode, NormalizeUserCode(userCode), deviceCode, DEVICE_CODE_DURATION)
+	if err != nil {
+		return "", nil, err
+	}
+	if !created {
+		return "", nil, errors.New("user code collision")
+	}
+
+	err = Tokens.Put(PurposeDeviceCode, deviceCode, request, DEVICE_CODE_DURATION+DEVICE_CODE_RETENTION)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return deviceCode, request, nil
+}
+
+// FindDeviceAuthorization looks up a pending request by the code the user entered
+func FindDeviceAuthorization(userCode string) (string, *DeviceAuthorization, error) {
+	var deviceCode string
+	err := Tokens.Get(PurposeUserCode, NormalizeUserCode(userCode), &deviceCode)
+	if err != nil {
+		return "", nil, ErrUserCodeInvalid
+	}
+
+	var request DeviceAuthorization
+	err = Tokens.Get(PurposeDeviceCode, deviceCode, &request)
+	if err != nil || request.Status != DEVICE_STATUS_PENDING || time.Now().After(request.ExpiresAt) {
+		return "", nil, ErrUserCodeInvalid
+	}
+
+	return deviceCode, &request, nil
+}
+
+// CompleteDeviceAuthorization records the decision of the user.
+// The user code can not be entered again.
+func CompleteDeviceAuthorization(userCode string, userId string, authTime time.Time, approved bool) (*DeviceAuthorization, error) {
+	deviceCode, request, err := FindDeviceAuthorization(userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Status = DEVICE_STATUS_DENIED
+	if approved {
+		request.Status = DEVICE_STATUS_APPROVED
+		request.UserID = userId
+		request.AuthTime = authTime
+	}
+
+	err = Tokens.Put(PurposeDeviceCode, deviceCode, request, time.Until(request.ExpiresAt)+DEVICE_CODE_RETENTION)
+	if err != nil {
+		return nil, err
+	}
+
+	err = Tokens.Delete(PurposeUserCode, NormalizeUserCode(userCode))
+	if err != nil {
+		log.Error("Fail delete user code: ", err)
+	}
+
+	return request, nil
+}
+
+// PollDeviceAuthorization returns the request of the client. Polling faster
+// than the interval returns ErrDeviceSlowDown and raises the interval by
+// DEVICE_SLOW_DOWN_INCREMENT. A decided request is returned once, the device
+// code can not be used afterwards. The raised interval is kept in its own
+// record, so a poll never writes back a request the user may have decided.
+func PollDeviceAuthorization(deviceCode string, clientId string) (*DeviceAuthorization, error) {
+	var request DeviceAuthorization
+	err := Tokens.Get(PurposeDeviceCode, deviceCode, &request)
+	if err != nil || request.ClientID != clientId {
+		return nil, ErrDeviceCodeInvalid
+	}
+
+	if time.Now().After(request.ExpiresAt) {
+		return nil, ErrDeviceCodeExpired
+	}
+
+	if request.Interval == 0 {
+		request.Interval = DEVICE_POLLING_INTERVAL
+	}
+	var interval time.Duration
+	if err = Tokens.Get(PurposeDeviceInterval, deviceCode, &interval); err == nil && interval > request.Interval {
+		request.Interval = interval
+	}
+
+	// The poll marker expires after one interval
+	fresh, err := Tokens.PutNX(PurposeDevicePoll, deviceCode, true, request.Interval)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		request.Interval += DEVICE_SLOW_DOWN_INCREMENT
+		err = Tokens.Put(PurposeDeviceInterval, deviceCode, request.Interval, time.Until(request.ExpiresAt)+DEVICE_CODE_RETENTION)
+		if err != nil {
+			return nil, err
+		}
+
+		// The next poll must wait for the raised interval
+		err = Tokens.Put(PurposeDevicePoll, deviceCode, true, request.Interval)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrDeviceSlowDown
+	}
+
+	if request.Status == DEVICE_STATUS_PENDING {
+		return &request, nil
+	}
+
+	// Only one poll receives the decision
+	err = Tokens.Consume(PurposeDeviceCode, deviceCode, &request)
+	if err != nil {
+		return nil, ErrDeviceCodeInvalid
+	}
+
+	return &request, nil
+}
+
+// CountUserCodeEntry counts a user code entered in the session and from
+// the IP, ErrUserCodeEntries is returned once either is over its limit
+func CountUserCodeEntry(sessionId string, ip string) error {
+	perSession, err := Tokens.Incr(PurposeUserCodeEntry, "session:"+sessionId, USER_CODE_ENTRY_WINDOW)
+	if err != nil {
+		return err
+	}
+
+	perIP, err := Tokens.Incr(PurposeUserCodeEntry, "ip:"+ip, USER_CODE_ENTRY_WINDOW)
+	if err != nil {
+		return err
+	}
+
+	if perSession > USER_CODE_ENTRIES_PER_SESSION || perIP > USER_CODE_ENTRIES_PER_IP {
+		return ErrUserCodeEntries
+	}
+
+	return nil
+}
+
+// NormalizeUserCode ignores case and separators the user typed
+func NormalizeUserCode(userCode string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if strings.ContainsRune(USER_CODE_CHARSET, r) {
+			return r
+		}
+		return -1
+	}, userCode)
+}
+
+// newUserCode returns a code formatted as XXXX-XXXX
+func newUserCode() (string, error) {
+	var code strings.Builder
+	max := big.NewInt(int64(len(USER_CODE_CHARSET)))
+	for i := 0; i < USER_CODE_LENGTH; i++ {
+		if i == USER_CODE_LENGTH/2 {
+			code.WriteByte('-')
+		}
+
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(USER_CODE_CHARSET[n.Int64()])
+	}
+
+	return code.String(), nil
+}
@@ -0,0 +1,56 @@
+package sessionModel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollDeviceAuthorization(t *testing.T) {
+	server := newTestRedis(t)
+
+	deviceCode, request, err := NewDeviceAuthorization("tv", "openid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = PollDeviceAuthorization(deviceCode, "other"); err != ErrDeviceCodeInvalid {
+		t.Errorf("other client: got %v want %v", err, ErrDeviceCodeInvalid)
+	}
+
+	polled, err := PollDeviceAuthorization(deviceCode, "tv")
+	if err != nil || polled.Status != DEVICE_STATUS_PENDING {
+		t.Fatalf("first poll: got %v, %v", polled, err)
+	}
+
+	// The user approves while the device polls too fast
+	_, err = CompleteDeviceAuthorization(request.UserCode, "5c3a1b2e9d4f6a7b8c9d0e1f", time.Now(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = PollDeviceAuthorization(deviceCode, "tv"); err != ErrDeviceSlowDown {
+		t.Fatalf("fast poll: got %v want %v", err, ErrDeviceSlowDown)
+	}
+
+	// slow_down must not write back the request it read before the approval
+	var stored DeviceAuthorization
+	err = Tokens.Get(PurposeDeviceCode, deviceCode, &stored)
+	if err != nil || stored.Status != DEVICE_STATUS_APPROVED || stored.Interval != DEVICE_POLLING_INTERVAL {
+		t.Errorf("stored request: got %+v, %v", stored, err)
+	}
+
+	// The raised interval applies to the next poll
+	server.FastForward(DEVICE_POLLING_INTERVAL)
+	if _, err = PollDeviceAuthorization(deviceCode, "tv"); err != ErrDeviceSlowDown {
+		t.Fatalf("poll within the raised interval: got %v want %v", err, ErrDeviceSlowDown)
+	}
+
+	server.FastForward(DEVICE_POLLING_INTERVAL + 2*DEVICE_SLOW_DOWN_INCREMENT)
+	polled, err = PollDeviceAuthorization(deviceCode, "tv")
+	if err != nil || polled.Status != DEVICE_STATUS_APPROVED {
+		t.Fatalf("poll after the interval: got %v, %v", polled, err)
+	}
+
+	if _, err = PollDeviceAuthorization(deviceCode, "tv"); err != ErrDeviceCodeInvalid {
+		t.Errorf("used device code: got %v want %v", err, ErrDeviceCodeInvalid)
+	}
+}
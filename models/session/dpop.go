@@ -0,0 +1,139 @@
+package sessionModel
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+	"github.com/handymesh/hyshAuthService/utils/jose"
+)
+
+const (
+	DPOP_PROOF_TYPE = "dpop+jwt"
+
+	// accepted age of a proof, and clock skew of the client
+	DPOP_PROOF_MAX_AGE = time.Minute * 5
+	DPOP_PROOF_LEEWAY  = time.Second * 30
+
+	DPOP_JTI_PREFIX = "jti:dpop:"
+)
+
+var (
+	// DPoPAlgs are the accepted proof algorithms, asymmetric only
+	DPoPAlgs = []string{"RS256", "ES256", "EdDSA"}
+
+	ErrDPoPProofInvalid = errors.New("DPoP proof invalid")
+	ErrDPoPProofReused  = errors.New("DPoP proof reused")
+)
+
+// Confirmation binds a token to the key of its holder (RFC 7800).
+// JKT is the JWK SHA-256 thumbprint of the DPoP key.
+type Confirmation struct {
+	JKT string `json:"jkt"`
+}
+
+// dpopClaims of a DPoP proof (RFC 9449, section 4.2)
+type dpopClaims struct {
+	Id       string `json:"jti"`
+	Method   string `json:"htm"`
+	URI      string `json:"htu"`
+	IssuedAt int64  `json:"iat"`
+	// hash of the access token sent along with the proof
+	AccessTokenHash string `json:"ath,omitempty"`
+}
+
+func (c *dpopClaims) Valid() error {
+	issuedAt := time.Unix(c.IssuedAt, 0)
+	if c.Id == "" || c.IssuedAt == 0 {
+		return ErrDPoPProofInvalid
+	}
+	if time.Since(issuedAt) > DPOP_PROOF_MAX_AGE || time.Until(issuedAt) > DPOP_PROOF_LEEWAY {
+		return errors.New("DPoP proof expired")
+	}
+	return nil
+}
+
+// VerifyDPoPProof checks the DPoP proof of a request to the HTTP method and URI.
+// With an access token the proof must carry its hash. The proof is usable once.
+// It returns the thumbprint of the key the proof was signed with.
+func VerifyDPoPProof(proof string, method string, uri string, accessToken string) (string, error) {
+	var jwk jose.JWK
+	claims := &dpopClaims{}
+
+	parser := &jwt.Parser{ValidMethods: DPoPAlgs}
+	_, err := parser.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != DPOP_PROOF_TYPE {
+			return nil, errors.New("unexpected proof type")
+		}
+
+		// The public key comes with the proof
+		header, ok := token.Header["jwk"].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("proof key missing")
+		}
+		if _, private := header["d"]; private {
+			return nil, errors.New("proof key must be public")
+		}
+
+		b, err := json.Marshal(header)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(b, &jwk); err != nil {
+			return nil, err
+		}
+
+		return jwk.PublicKey()
+	})
+	if err != nil {
+		return "", ErrDPoPProofInvalid
+	}
+
+	if !strings.EqualFold(claims.Method, method) || !sameURI(claims.URI, uri) {
+		return "", ErrDPoPProofInvalid
+	}
+
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		if claims.AccessTokenHash != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return "", ErrDPoPProofInvalid
+		}
+	}
+
+	jkt, err := jwk.Thumbprint()
+	if err != nil {
+		return "", ErrDPoPProofInvalid
+	}
+
+	// Replay protection for the whole window a proof is accepted in
+	fresh, err := redis.Redis.SetNX(DPOP_JTI_PREFIX+jkt+":"+claims.Id, "true", DPOP_PROOF_MAX_AGE+DPOP_PROOF_LEEWAY).Result()
+	if err != nil {
+		return "", err
+	}
+	if !fresh {
+		return "", ErrDPoPProofReused
+	}
+
+	return jkt, nil
+}
+
+// sameURI compares the htu claim without query and fragment (RFC 9449, section 4.3)
+func sameURI(htu string, uri string) bool {
+	a, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host) && a.EscapedPath() == b.EscapedPath()
+}
@@ -0,0 +1,49 @@
+package sessionModel
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+)
+
+const (
+	// SECURITY_EVENTS_CHANNEL is the Redis channel security events are published to
+	SECURITY_EVENTS_CHANNEL = "security_events"
+
+	EVENT_REFRESH_TOKEN_REUSE = "refresh_token_reuse"
+)
+
+// SecurityEvent describes suspicious activity around a user's tokens
+type SecurityEvent struct {
+	Type      string    `json:"type"`
+	UserID    string    `json:"user_id,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// EmitSecurityEvent logs the event and publishes it for other services
+func EmitSecurityEvent(event SecurityEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	log.WithFields(logrus.Fields{
+		"event":      event.Type,
+		"user_id":    event.UserID,
+		"session_id": event.SessionID,
+	}).Warn("security event")
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	err = redis.Redis.Publish(SECURITY_EVENTS_CHANNEL, payload).Err()
+	if err != nil {
+		log.Error("Fail publish security event: ", err)
+	}
+}
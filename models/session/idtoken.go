@@ -0,0 +1,52 @@
+package sessionModel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+)
+
+// IDClaims of an OpenID Connect ID token
+type IDClaims struct {
+	jwt.StandardClaims
+	Nonce         string `json:"nonce,omitempty"`
+	AuthTime      int64  `json:"auth_time,omitempty"`
+	SessionID     string `json:"sid,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Locale        string `json:"locale,omitempty"`
+}
+
+var ErrAudienceClient = errors.New("client id is the access token audience")
+
+// NewIDToken signs an ID token for the client the user signed in to
+func NewIDToken(claims *IDClaims, clientId string) (string, error) {
+	// An ID token for the API audience would pass as an access token
+	if clientId == "" || clientId == AUDIENCE {
+		return "", ErrAudienceClient
+	}
+
+	signingKey := activeKey()
+	if signingKey == nil {
+		return "", errors.New("signing key is not loaded")
+	}
+
+	jti, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.Id = jti.String()
+	claims.Issuer = ISSUER
+	claims.Audience = clientId
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(ClientLifetimes(clientId).IDToken).Unix()
+
+	token := jwt.NewWithClaims(signingKey.Method, claims)
+	token.Header["kid"] = signingKey.Kid
+	return token.SignedString(signingKey.PrivateKey)
+}
@@ -0,0 +1,424 @@
+package sessionModel
+
+import (
+	"errors"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/handymesh/hyshAuthService/db/mongodb"
+	"github.com/handymesh/hyshAuthService/db/redis"
+	"github.com/handymesh/hyshAuthService/utils"
+	"github.com/handymesh/hyshAuthService/utils/crypto"
+	"github.com/handymesh/hyshAuthService/utils/jose"
+)
+
+const (
+	// CollectionKeys holds the name of the signing keys collection
+	CollectionKeys = "keys"
+
+	KEY_STATUS_ACTIVE  = "active"
+	KEY_STATUS_RETIRED = "retired"
+
+	// minimal pause between reloads triggered by an unknown kid
+	KEY_RELOAD_COOLDOWN = time.Second * 5
+	KEY_ROTATE_LOCK     = "lock:keys:rotate"
+)
+
+// storedKey is a signing key as persisted in MongoDB. The private key is
+// encrypted with JWT_KEY_ENCRYPTION_KEY, PrivateKey holds the plaintext PEM
+// of keys stored before encryption until they are migrated.
+type storedKey struct {
+	Kid                 string     `bson:"_id"`
+	Alg                 string     `bson:"alg"`
+	PrivateKey          string     `bson:"private_key,omitempty"`
+	EncryptedPrivateKey string     `bson:"encrypted_private_key,omitempty"`
+	Status              string     `bson:"status"`
+	CreatedAt           time.Time  `bson:"created_at"`
+	RetiredAt           *time.Time `bson:"retired_at,omitempty"`
+}
+
+// keyRing holds the active signing key and the retired keys
+// that are still accepted for verification
+type keyRing struct {
+	sync.RWMutex
+	active   *SigningKey
+	keys     map[string]*SigningKey
+	loadedAt time.Time
+}
+
+var (
+	ring = &keyRing{keys: map[string]*SigningKey{}}
+
+	// encrypts the private keys at rest
+	keyCipher *crypto.Cipher
+)
+
+// LoadSigningKeys loads the key ring from MongoDB. The first replica to start
+// seeds it with the key from JWT_PRIVATE_KEY_FILE or a generated JWT_SIGNING_ALG key.
+// Replicas then reload the ring every JWT_KEY_RELOAD_INTERVAL and rotate the
+// active key once it is older than JWT_KEY_ROTATION_INTERVAL (0 disables it).
+// Private keys are encrypted with JWT_KEY_ENCRYPTION_KEY, which is required.
+func LoadSigningKeys() {
+	// Get configuration
+	JWT_KEY_RELOAD_INTERVAL := utils.Getenv("JWT_KEY_RELOAD_INTERVAL", "30s")
+	JWT_KEY_ROTATION_INTERVAL := utils.Getenv("JWT_KEY_ROTATION_INTERVAL", "0")
+	JWT_KEY_ENCRYPTION_KEY := utils.Getenv("JWT_KEY_ENCRYPTION_KEY", "")
+
+	var err error
+	keyCipher, err = crypto.NewCipher(JWT_KEY_ENCRYPTION_KEY)
+	if err != nil {
+		log.Panic("Incorrect JWT_KEY_ENCRYPTION_KEY, generate one with `openssl rand -base64 32`: ", err)
+	}
+
+	reloadInterval, err := time.ParseDuration(JWT_KEY_RELOAD_INTERVAL)
+	if err != nil {
+		log.Panic("Incorrect JWT_KEY_RELOAD_INTERVAL: ", err)
+	}
+	rotationInterval, err := time.ParseDuration(JWT_KEY_ROTATION_INTERVAL)
+	if err != nil {
+		log.Panic("Incorrect JWT_KEY_ROTATION_INTERVAL: ", err)
+	}
+
+	err = reloadKeys()
+	if err != nil {
+		log.Panic("Fail load signing keys: ", err)
+	}
+
+	if activeKey() == nil {
+		key, err := seedKey()
+		if err != nil {
+			log.Panic("Fail create signing key: ", err)
+		}
+
+		err = insertKey(key)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			log.Panic("Fail save signing key: ", err)
+		}
+
+		err = reloadKeys()
+		if err != nil || activeKey() == nil {
+			log.Panic("Fail load signing keys: ", err)
+		}
+	}
+
+	key := activeKey()
+	log.Info("Signing key ", key.Kid, " (", key.Method.Alg(), ")")
+
+	go watchKeys(reloadInterval, rotationInterval)
+}
+
+// RotateSigningKey generates a new active key with the algorithm of the current one.
+// The previous key is retired and stays in JWKS until tokens signed by it expire.
+func RotateSigningKey() (*SigningKey, error) {
+	return rotateSigningKey(0)
+}
+
+// rotateSigningKey rotates the active key, unless maxAge is set and the
+// active key is younger. The age is checked again under the lock, another
+// replica may have rotated the key since this one last reloaded the ring.
+// It returns nil without error when the rotation is skipped.
+func rotateSigningKey(maxAge time.Duration) (*SigningKey, error) {
+	// Only one replica rotates at a time
+	locked, err := redis.Redis.SetNX(KEY_ROTATE_LOCK, "true", time.Minute).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, errors.New("key rotation is already in progress")
+	}
+	defer redis.Redis.Del(KEY_ROTATE_LOCK)
+
+	if maxAge > 0 {
+		err = reloadKeys()
+		if err != nil {
+			return nil, err
+		}
+
+		current := activeKey()
+		if current != nil && time.Since(current.CreatedAt) <= maxAge {
+			return nil, nil
+		}
+	}
+
+	alg := utils.Getenv("JWT_SIGNING_ALG", "RS256")
+	if current := activeKey(); current != nil {
+		alg = current.Method.Alg()
+	}
+
+	key, err := NewSigningKey(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retire all other active keys
+	now := time.Now()
+	_, err = keysCollection().UpdateMany(nil,
+		bson.M{"status": KEY_STATUS_ACTIVE, "_id": bson.M{"$ne": key.Kid}},
+		bson.M{"$set": bson.M{"status": KEY_STATUS_RETIRED, "retired_at": now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pruneKeys()
+	if err != nil {
+		log.Error("Fail prune signing keys: ", err)
+	}
+
+	err = reloadKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Rotate signing key to ", key.Kid)
+
+	return key, nil
+}
+
+// SigningKeys returns the active key followed by retired keys, newest first
+func SigningKeys() []*SigningKey {
+	ring.RLock()
+	defer ring.RUnlock()
+
+	keys := make([]*SigningKey, 0, len(ring.keys))
+	for _, key := range ring.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	return keys
+}
+
+// ActiveSigningKey returns the key signing new tokens
+func ActiveSigningKey() *SigningKey {
+	return activeKey()
+}
+
+// JWKS returns the public keys accepted for token verification
+func JWKS() jose.JWKS {
+	jwks := jose.JWKS{Keys: []jose.JWK{}}
+
+	for _, key := range SigningKeys() {
+		jwk, err := key.JWK()
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+func activeKey() *SigningKey {
+	ring.RLock()
+	defer ring.RUnlock()
+
+	return ring.active
+}
+
+func lookupKey(kid string) *SigningKey {
+	ring.RLock()
+	defer ring.RUnlock()
+
+	return ring.keys[kid]
+}
+
+// ringKey resolves a signing key by kid. An unknown kid reloads
+// the ring first, another replica may have rotated the key.
+func ringKey(kid string) *SigningKey {
+	key := lookupKey(kid)
+	if key != nil {
+		return key
+	}
+
+	ring.RLock()
+	stale := time.Since(ring.loadedAt) > KEY_RELOAD_COOLDOWN
+	ring.RUnlock()
+
+	if stale {
+		err := reloadKeys()
+		if err != nil {
+			log.Error("Fail reload signing keys: ", err)
+		}
+		key = lookupKey(kid)
+	}
+
+	return key
+}
+
+func watchKeys(reloadInterval time.Duration, rotationInterval time.Duration) {
+	for range time.Tick(reloadInterval) {
+		err := reloadKeys()
+		if err != nil {
+			log.Error("Fail reload signing keys: ", err)
+			continue
+		}
+
+		key := activeKey()
+		if rotationInterval > 0 && key != nil && time.Since(key.CreatedAt) > rotationInterval {
+			_, err = rotateSigningKey(rotationInterval)
+			if err != nil {
+				log.Error("Fail rotate signing key: ", err)
+			}
+		}
+	}
+}
+
+// reloadKeys replaces the in-memory ring with the keys stored in MongoDB
+func reloadKeys() error {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := keysCollection().Find(nil, bson.M{
+		"$or": bson.A{
+			bson.M{"status": KEY_STATUS_ACTIVE},
+			bson.M{"status": KEY_STATUS_RETIRED, "retired_at": bson.M{"$gt": time.Now().Add(-keyRetention())}},
+		},
+	}, opts)
+	if err != nil {
+		return err
+	}
+
+	var stored []storedKey
+	if err = cursor.All(nil, &stored); err != nil {
+		return err
+	}
+
+	var active *SigningKey
+	keys := map[string]*SigningKey{}
+	for _, item := range stored {
+		key, err := decryptKey(item)
+		if err != nil {
+			log.Error("Fail parse signing key ", item.Kid, ": ", err)
+			continue
+		}
+		key.CreatedAt = item.CreatedAt
+		key.RetiredAt = item.RetiredAt
+
+		// The newest active key signs, older ones are only verified
+		if item.Status == KEY_STATUS_ACTIVE && active == nil {
+			active = key
+		}
+		keys[key.Kid] = key
+	}
+
+	ring.Lock()
+	ring.active = active
+	ring.keys = keys
+	ring.loadedAt = time.Now()
+	ring.Unlock()
+
+	return nil
+}
+
+// pruneKeys removes retired keys that can no longer verify any token
+func pruneKeys() error {
+	_, err := keysCollection().DeleteMany(nil, bson.M{
+		"status":     KEY_STATUS_RETIRED,
+		"retired_at": bson.M{"$lte": time.Now().Add(-keyRetention())},
+	})
+
+	return err
+}
+
+// keyRetention is how long a retired key verifies tokens: the longest
+// lifetime of a token signed right before the rotation, plus the leeway
+func keyRetention() time.Duration {
+	return ACCESS_TOKEN_MAX_DURATION + JWT_LEEWAY
+}
+
+func insertKey(key *SigningKey) error {
+	encryptedKey, err := encryptKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = keysCollection().InsertOne(nil, storedKey{
+		Kid:                 key.Kid,
+		Alg:                 key.Method.Alg(),
+		EncryptedPrivateKey: encryptedKey,
+		Status:              KEY_STATUS_ACTIVE,
+		CreatedAt:           key.CreatedAt,
+	})
+
+	return err
+}
+
+// encryptKey seals the PEM of the private key, bound to its kid
+func encryptKey(key *SigningKey) (string, error) {
+	privateKey, err := key.MarshalPEM()
+	if err != nil {
+		return "", err
+	}
+
+	return keyCipher.Encrypt(privateKey, []byte(key.Kid))
+}
+
+// decryptKey opens a stored key. A key stored in plaintext
+// before encryption is encrypted in place.
+func decryptKey(item storedKey) (*SigningKey, error) {
+	if item.EncryptedPrivateKey != "" {
+		privateKey, err := keyCipher.Decrypt(item.EncryptedPrivateKey, []byte(item.Kid))
+		if err != nil {
+			return nil, err
+		}
+		return ParseSigningKey(privateKey)
+	}
+
+	key, err := ParseSigningKey([]byte(item.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := encryptKey(key)
+	if err != nil {
+		return nil, err
+	}
+	_, err = keysCollection().UpdateOne(nil,
+		bson.M{"_id": item.Kid, "private_key": bson.M{"$exists": true}},
+		bson.M{"$set": bson.M{"encrypted_private_key": encryptedKey}, "$unset": bson.M{"private_key": ""}},
+	)
+	if err != nil {
+		log.Error("Fail encrypt signing key ", item.Kid, ": ", err)
+	} else {
+		log.Info("Encrypt signing key ", item.Kid)
+	}
+
+	return key, nil
+}
+
+// seedKey reads JWT_PRIVATE_KEY_FILE or generates a JWT_SIGNING_ALG key
+func seedKey() (*SigningKey, error) {
+	// Get configuration
+	JWT_PRIVATE_KEY_FILE := utils.Getenv("JWT_PRIVATE_KEY_FILE", "")
+	JWT_SIGNING_ALG := utils.Getenv("JWT_SIGNING_ALG", "RS256")
+
+	if JWT_PRIVATE_KEY_FILE == "" {
+		log.Warn("JWT_PRIVATE_KEY_FILE is not set, generate ", JWT_SIGNING_ALG, " signing key")
+		return NewSigningKey(JWT_SIGNING_ALG)
+	}
+
+	log.Info("JWT_PRIVATE_KEY_FILE", " ", JWT_PRIVATE_KEY_FILE)
+	b, err := ioutil.ReadFile(JWT_PRIVATE_KEY_FILE)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSigningKey(b)
+}
+
+func keysCollection() *mongo.Collection {
+	return mongodb.Session.Database("auth").Collection(CollectionKeys)
+}
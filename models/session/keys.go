@@ -0,0 +1,141 @@
+package sessionModel
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/handymesh/hyshAuthService/utils/jose"
+)
+
+// SigningKey is an asymmetric key used to sign tokens, identified by its kid
+type SigningKey struct {
+	Kid        string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	CreatedAt  time.Time
+	RetiredAt  *time.Time
+}
+
+// NewSigningKey generates a key for the given JWS algorithm
+func NewSigningKey(alg string) (*SigningKey, error) {
+	var privateKey crypto.Signer
+	var err error
+
+	switch alg {
+	case "RS256":
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, errors.New("unsupported signing algorithm " + alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newSigningKey(privateKey)
+}
+
+// ParseSigningKey reads a PEM encoded PKCS#8, PKCS#1 (RSA) or SEC 1 (EC) private key
+func ParseSigningKey(pemBytes []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var privateKey interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		privateKey, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key")
+	}
+
+	return newSigningKey(signer)
+}
+
+func newSigningKey(privateKey crypto.Signer) (*SigningKey, error) {
+	var method jwt.SigningMethod
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("unsupported EC curve " + key.Curve.Params().Name)
+		}
+		method = jwt.SigningMethodES256
+	case ed25519.PrivateKey:
+		method = jose.SigningMethodEdDSA
+	default:
+		return nil, errors.New("unsupported private key")
+	}
+
+	jwk, err := jose.NewJWK(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		Kid:        kid,
+		Method:     method,
+		PrivateKey: privateKey,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// PublicKey returns the key used to verify signatures
+func (k *SigningKey) PublicKey() crypto.PublicKey {
+	return k.PrivateKey.Public()
+}
+
+// JWK returns the public part of the key as a JWK
+func (k *SigningKey) JWK() (jose.JWK, error) {
+	jwk, err := jose.NewJWK(k.PublicKey())
+	if err != nil {
+		return jwk, err
+	}
+
+	jwk.Kid = k.Kid
+	jwk.Alg = k.Method.Alg()
+	jwk.Use = "sig"
+
+	return jwk, nil
+}
+
+// MarshalPEM encodes the private key as PKCS#8 PEM
+func (k *SigningKey) MarshalPEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
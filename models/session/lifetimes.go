@@ -0,0 +1,130 @@
+package sessionModel
+
+import (
+	"sync"
+	"time"
+
+	clientModel "github.com/handymesh/hyshAuthService/models/client"
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+var (
+	// Get configuration
+	ACCESS_TOKEN_DURATION           = durationEnv("ACCESS_TOKEN_DURATION", "1h")
+	ID_TOKEN_DURATION               = durationEnv("ID_TOKEN_DURATION", "1h")
+	REFRESH_TOKEN_IDLE_DURATION     = durationEnv("REFRESH_TOKEN_IDLE_DURATION", "336h")     // 14 days
+	REFRESH_TOKEN_ABSOLUTE_DURATION = durationEnv("REFRESH_TOKEN_ABSOLUTE_DURATION", "720h") // 30 days
+	RECOVERY_LINK_DURATION          = durationEnv("RECOVERY_LINK_DURATION", "1h")
+
+	// Upper bounds of the per-client overrides. Revocation records
+	// and retired signing keys are kept for these durations.
+	ACCESS_TOKEN_MAX_DURATION  = durationEnv("ACCESS_TOKEN_MAX_DURATION", "24h")
+	REFRESH_TOKEN_MAX_DURATION = durationEnv("REFRESH_TOKEN_MAX_DURATION", "2160h") // 90 days
+)
+
+// Client overrides are read again after this long
+const CLIENT_LIFETIMES_CACHE_TTL = time.Minute
+
+// lifetimesCache keeps the lifetimes of the clients that got tokens recently,
+// so issuing a token does not read the client registry
+type lifetimesCache struct {
+	sync.Mutex
+	entries map[string]cachedLifetimes
+}
+
+type cachedLifetimes struct {
+	lifetimes Lifetimes
+	expiresAt time.Time
+}
+
+var clientLifetimes = &lifetimesCache{entries: map[string]cachedLifetimes{}}
+
+// Lifetimes of the tokens issued to a client
+type Lifetimes struct {
+	AccessToken time.Duration
+	IDToken     time.Duration
+	// A session ends when its refresh token is not used for RefreshIdle,
+	// and at the latest RefreshAbsolute after sign-in
+	RefreshIdle     time.Duration
+	RefreshAbsolute time.Duration
+	RecoveryLink    time.Duration
+}
+
+// ClientLifetimes returns the configured lifetimes with the overrides of the client.
+// Unknown clients, like the first-party web client, use the configured ones.
+// Changed overrides apply within CLIENT_LIFETIMES_CACHE_TTL.
+func ClientLifetimes(clientId string) Lifetimes {
+	if clientId == "" {
+		return readClientLifetimes(clientId)
+	}
+
+	now := time.Now()
+	clientLifetimes.Lock()
+	entry, ok := clientLifetimes.entries[clientId]
+	clientLifetimes.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.lifetimes
+	}
+
+	lifetimes := readClientLifetimes(clientId)
+
+	clientLifetimes.Lock()
+	for id, entry := range clientLifetimes.entries {
+		if now.After(entry.expiresAt) {
+			delete(clientLifetimes.entries, id)
+		}
+	}
+	clientLifetimes.entries[clientId] = cachedLifetimes{lifetimes: lifetimes, expiresAt: now.Add(CLIENT_LIFETIMES_CACHE_TTL)}
+	clientLifetimes.Unlock()
+
+	return lifetimes
+}
+
+func readClientLifetimes(clientId string) Lifetimes {
+	lifetimes := Lifetimes{
+		AccessToken:     ACCESS_TOKEN_DURATION,
+		IDToken:         ID_TOKEN_DURATION,
+		RefreshIdle:     REFRESH_TOKEN_IDLE_DURATION,
+		RefreshAbsolute: REFRESH_TOKEN_ABSOLUTE_DURATION,
+		RecoveryLink:    RECOVERY_LINK_DURATION,
+	}
+
+	if clientId == "" {
+		return lifetimes
+	}
+	client, err := clientModel.FindByID(clientId)
+	if err != nil {
+		return lifetimes
+	}
+
+	override := func(current *time.Duration, seconds int64, max time.Duration) {
+		if seconds <= 0 {
+			return
+		}
+		*current = time.Duration(seconds) * time.Second
+		if *current > max {
+			*current = max
+		}
+	}
+	override(&lifetimes.AccessToken, client.AccessTokenLifetime, ACCESS_TOKEN_MAX_DURATION)
+	override(&lifetimes.IDToken, client.IDTokenLifetime, ACCESS_TOKEN_MAX_DURATION)
+	override(&lifetimes.RefreshIdle, client.RefreshTokenIdleLifetime, REFRESH_TOKEN_MAX_DURATION)
+	override(&lifetimes.RefreshAbsolute, client.RefreshTokenLifetime, REFRESH_TOKEN_MAX_DURATION)
+	override(&lifetimes.RecoveryLink, client.RecoveryLinkLifetime, RECOVERY_LINK_DURATION)
+
+	return lifetimes
+}
+
+// durationEnv reads a duration from the environment,
+// an incorrect value falls back to the default
+func durationEnv(name string, defaultValue string) time.Duration {
+	value := utils.Getenv(name, defaultValue)
+	duration, err := time.ParseDuration(value)
+	if err == nil && duration > 0 {
+		return duration
+	}
+
+	log.Warn("Incorrect ", name, ": ", value, ", using ", defaultValue)
+	duration, _ = time.ParseDuration(defaultValue)
+	return duration
+}
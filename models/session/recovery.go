@@ -0,0 +1,50 @@
+package sessionModel
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRecoveryLinkInvalid = errors.New("recovery link invalid")
+
+// NewRecoveryLink issues a single-use password recovery token for the user
+// of the client, valid for the recovery link lifetime of the client
+func NewRecoveryLink(userId string, clientId string) (string, error) {
+	token, err := NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = Tokens.Put(PurposeRecovery, token, RecoveryLink{
+		UserID:    userId,
+		CreatedAt: time.Now(),
+	}, ClientLifetimes(clientId).RecoveryLink)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ReserveRecoveryRequest records a recovery request by its idempotency key
+// for the recovery link lifetime of the client. It returns false when the
+// request was already made, no other link is issued for it then.
+func ReserveRecoveryRequest(key string, clientId string) (bool, error) {
+	return Tokens.PutNX(PurposeRecoveryRequest, key, true, ClientLifetimes(clientId).RecoveryLink)
+}
+
+// ReleaseRecoveryRequest lets a failed recovery request be retried with its key
+func ReleaseRecoveryRequest(key string) error {
+	return Tokens.Delete(PurposeRecoveryRequest, key)
+}
+
+// ConsumeRecoveryLink returns the recovery link record and invalidates the link
+func ConsumeRecoveryLink(token string) (*RecoveryLink, error) {
+	var link RecoveryLink
+	err := Tokens.Consume(PurposeRecovery, token, &link)
+	if err != nil {
+		return nil, ErrRecoveryLinkInvalid
+	}
+
+	return &link, nil
+}
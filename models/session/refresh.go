@@ -0,0 +1,148 @@
+package sessionModel
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token invalid")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+// NewRefreshToken issues the first refresh token of the session
+func NewRefreshToken(session *Session) (string, error) {
+	return newRefreshToken(session, Grant{
+		ClientID:  session.ClientID,
+		UserAgent: session.UserAgent,
+		IP:        session.IP,
+	})
+}
+
+// RotateRefreshToken consumes the refresh token and issues its successor in the same session.
+// Presenting an already consumed token revokes the whole session.
+func RotateRefreshToken(token string, grant Grant) (*Session, string, error) {
+	record, err := getRefreshToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Refresh tokens are bound to the client they were issued to
+	if record.ClientID != grant.ClientID {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+
+	session, err := liveSession(record)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Refresh tokens of a DPoP session need a proof of the same key
+	if session.JKT != "" && session.JKT != grant.JKT {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+
+	// The consumed marker outlives any refresh token of the session
+	consumed, err := Tokens.PutNX(PurposeRefreshConsumed, token, time.Now(), time.Until(session.Expiry()))
+	if err != nil {
+		return nil, "", err
+	}
+	if !consumed {
+		err = revokeSession(session)
+		if err != nil {
+			log.Error("Fail revoke session: ", err)
+		}
+
+		EmitSecurityEvent(SecurityEvent{
+			Type:      EVENT_REFRESH_TOKEN_REUSE,
+			UserID:    session.UserID,
+			SessionID: session.ID,
+		})
+
+		return nil, "", ErrRefreshTokenReused
+	}
+
+	err = touchSession(session, grant)
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshToken, err := newRefreshToken(session, grant)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return session, refreshToken, nil
+}
+
+// InspectRefreshToken returns the record and the session of a usable refresh token
+func InspectRefreshToken(token string) (*RefreshToken, *Session, error) {
+	record, err := getRefreshToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consumed, err := Tokens.Exists(PurposeRefreshConsumed, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if consumed {
+		return nil, nil, ErrRefreshTokenInvalid
+	}
+
+	session, err := liveSession(record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, session, nil
+}
+
+// liveSession returns the session of the refresh token unless it was revoked
+func liveSession(record *RefreshToken) (*Session, error) {
+	session, err := GetSession(record.SessionID)
+	if err != nil || time.Now().After(session.Expiry()) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	revoked, err := isUserRevokedSince(session.UserID, session.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	return session, nil
+}
+
+func newRefreshToken(session *Session, grant Grant) (string, error) {
+	refreshToken, err := NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = Tokens.Put(PurposeRefresh, refreshToken, RefreshToken{
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		ClientID:  session.ClientID,
+		IssuedAt:  time.Now(),
+		UserAgent: grant.UserAgent,
+		IP:        grant.IP,
+	}, time.Until(session.Expiry()))
+	if err != nil {
+		return "", err
+	}
+
+	return refreshToken, nil
+}
+
+func getRefreshToken(token string) (*RefreshToken, error) {
+	var record RefreshToken
+	err := Tokens.Get(PurposeRefresh, token, &record)
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	return &record, nil
+}
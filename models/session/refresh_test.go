@@ -0,0 +1,124 @@
+package sessionModel
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/go-redis/redis"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+)
+
+// newTestRedis points the session model to an in-memory Redis for the test
+func newTestRedis(t *testing.T) *miniredis.Miniredis {
+	server := miniredis.RunT(t)
+	redis.Redis = goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { redis.Redis.Close() })
+	return server
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	newTestRedis(t)
+
+	// The empty client uses the configured lifetimes without the client registry
+	newToken := func(t *testing.T, grant Grant) (*Session, string) {
+		session, err := NewSession("5c3a1b2e9d4f6a7b8c9d0e1f", grant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := NewRefreshToken(session)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return session, token
+	}
+
+	tests := []struct {
+		name string
+		// setup returns the token to present and the grant presenting it
+		setup func(t *testing.T) (string, Grant)
+		err   error
+	}{
+		{"fresh token", func(t *testing.T) (string, Grant) {
+			_, token := newToken(t, Grant{})
+			return token, Grant{}
+		}, nil},
+		{"unknown token", func(t *testing.T) (string, Grant) {
+			return "unknown", Grant{}
+		}, ErrRefreshTokenInvalid},
+		{"consumed token", func(t *testing.T) (string, Grant) {
+			_, token := newToken(t, Grant{})
+			if _, _, err := RotateRefreshToken(token, Grant{}); err != nil {
+				t.Fatal(err)
+			}
+			return token, Grant{}
+		}, ErrRefreshTokenReused},
+		{"other client", func(t *testing.T) (string, Grant) {
+			_, token := newToken(t, Grant{})
+			return token, Grant{ClientID: "other"}
+		}, ErrRefreshTokenInvalid},
+		{"DPoP session without proof", func(t *testing.T) (string, Grant) {
+			_, token := newToken(t, Grant{JKT: "thumbprint"})
+			return token, Grant{}
+		}, ErrRefreshTokenInvalid},
+		{"DPoP session with other key", func(t *testing.T) (string, Grant) {
+			_, token := newToken(t, Grant{JKT: "thumbprint"})
+			return token, Grant{JKT: "other"}
+		}, ErrRefreshTokenInvalid},
+		{"DPoP session with its key", func(t *testing.T) (string, Grant) {
+			_, token := newToken(t, Grant{JKT: "thumbprint"})
+			return token, Grant{JKT: "thumbprint"}
+		}, nil},
+		{"revoked session", func(t *testing.T) (string, Grant) {
+			session, token := newToken(t, Grant{})
+			if err := RevokeSession(session.UserID, session.ID); err != nil {
+				t.Fatal(err)
+			}
+			return token, Grant{}
+		}, ErrRefreshTokenInvalid},
+	}
+
+	for _, test := range tests {
+		token, grant := test.setup(t)
+		_, next, err := RotateRefreshToken(token, grant)
+		if err != test.err {
+			t.Errorf("[%s] got %v want %v", test.name, err, test.err)
+		}
+		if err == nil && (next == "" || next == token) {
+			t.Errorf("[%s] got no new refresh token", test.name)
+		}
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	newTestRedis(t)
+
+	session, err := NewSession("5c3a1b2e9d4f6a7b8c9d0e1f", Grant{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := NewRefreshToken(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, second, err := RotateRefreshToken(first, Grant{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A stolen copy of the first token is replayed
+	_, _, err = RotateRefreshToken(first, Grant{})
+	if err != ErrRefreshTokenReused {
+		t.Fatalf("replay: got %v want %v", err, ErrRefreshTokenReused)
+	}
+
+	// The legitimate successor dies with the session
+	_, _, err = RotateRefreshToken(second, Grant{})
+	if err != ErrRefreshTokenInvalid {
+		t.Errorf("successor: got %v want %v", err, ErrRefreshTokenInvalid)
+	}
+	if _, err = GetSession(session.ID); err != ErrSessionNotFound {
+		t.Errorf("session: got %v want %v", err, ErrSessionNotFound)
+	}
+}
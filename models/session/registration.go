@@ -0,0 +1,41 @@
+package sessionModel
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInitialAccessTokenInvalid = errors.New("initial access token invalid")
+
+// NewInitialAccessToken issues a single-use token that allows
+// a partner to register one client (RFC 7591, section 3)
+func NewInitialAccessToken(ttl time.Duration) (string, *InitialAccessToken, error) {
+	token, err := NewOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	record := &InitialAccessToken{
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	err = Tokens.Put(PurposeInitialAccess, token, record, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, record, nil
+}
+
+// ConsumeInitialAccessToken checks the initial access token and invalidates it
+func ConsumeInitialAccessToken(token string) (*InitialAccessToken, error) {
+	var record InitialAccessToken
+	err := Tokens.Consume(PurposeInitialAccess, token, &record)
+	if err != nil {
+		return nil, ErrInitialAccessTokenInvalid
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,109 @@
+package sessionModel
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+)
+
+const (
+	REVOKED_TOKEN_PREFIX  = "revoked:jti:"
+	REVOKED_BEFORE_PREFIX = "revoked:before:"
+)
+
+var ErrTokenRevoked = errors.New("token revoked")
+
+// RevokeAccessToken denylists the token by its jti for the rest of its lifetime
+func RevokeAccessToken(claims *Claims) error {
+	ttl := time.Until(time.Unix(claims.ExpiresAt, 0))
+	if ttl <= 0 {
+		return nil
+	}
+
+	return redis.Redis.Set(REVOKED_TOKEN_PREFIX+claims.Id, "true", ttl).Err()
+}
+
+// RevokeUserTokens invalidates every access and refresh token issued to the user until now
+func RevokeUserTokens(userId string) error {
+	return redis.Redis.Set(REVOKED_BEFORE_PREFIX+userId, time.Now().UnixNano(), REFRESH_TOKEN_MAX_DURATION).Err()
+}
+
+// IsRevoked checks the denylist, the revoked sessions and the user's revocation watermark
+func IsRevoked(claims *Claims) (bool, error) {
+	values, err := redis.Redis.MGet(
+		REVOKED_TOKEN_PREFIX+claims.Id,
+		REVOKED_SESSION_PREFIX+claims.SessionID,
+		REVOKED_BEFORE_PREFIX+claims.Subject,
+	).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if values[0] != nil {
+		return true, nil
+	}
+
+	if claims.SessionID != "" && values[1] != nil {
+		return true, nil
+	}
+
+	watermark, ok := parseWatermark(values[2])
+	if !ok {
+		return false, nil
+	}
+
+	// iat has a precision of a second. In the second of the revocation
+	// only tokens of a session started after it are kept.
+	switch {
+	case claims.IssuedAt < watermark.Unix():
+		return true, nil
+	case claims.IssuedAt > watermark.Unix():
+		return false, nil
+	case claims.SessionID == "":
+		return true, nil
+	}
+
+	session, err := GetSession(claims.SessionID)
+	if err != nil {
+		return true, nil
+	}
+
+	return !session.CreatedAt.After(watermark), nil
+}
+
+// isUserRevokedSince reports whether tokens issued to the user at issuedAt were revoked
+func isUserRevokedSince(userId string, issuedAt time.Time) (bool, error) {
+	values, err := redis.Redis.MGet(REVOKED_BEFORE_PREFIX + userId).Result()
+	if err != nil {
+		return false, err
+	}
+
+	watermark, ok := parseWatermark(values[0])
+	if !ok {
+		return false, nil
+	}
+
+	return !issuedAt.After(watermark), nil
+}
+
+// parseWatermark reads the time of the last revocation of every token of a user
+func parseWatermark(value interface{}) (time.Time, bool) {
+	watermark, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	before, err := strconv.ParseInt(watermark, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// Watermarks were stored in seconds before
+	if before < 1e12 {
+		return time.Unix(before, 0), true
+	}
+
+	return time.Unix(0, before), true
+}
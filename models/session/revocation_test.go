@@ -0,0 +1,82 @@
+package sessionModel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+)
+
+func TestRevokeUserTokens(t *testing.T) {
+	newTestRedis(t)
+
+	const userId = "5c3a1b2e9d4f6a7b8c9d0e1f"
+	accessClaims := func(session *Session) *Claims {
+		return &Claims{
+			StandardClaims: jwt.StandardClaims{Id: session.ID, Subject: userId, IssuedAt: session.CreatedAt.Unix()},
+			SessionID:      session.ID,
+		}
+	}
+
+	before, err := NewSession(userId, Grant{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	beforeRefresh, err := NewRefreshToken(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = RevokeUserTokens(userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watermark, ok := parseWatermark(redis.Redis.Get(REVOKED_BEFORE_PREFIX + userId).Val())
+	if !ok {
+		t.Fatal("no watermark")
+	}
+	claimsAt := func(issuedAt time.Time) *Claims {
+		return &Claims{StandardClaims: jwt.StandardClaims{Subject: userId, IssuedAt: issuedAt.Unix()}}
+	}
+
+	// A sign-in right after the revocation, usually within the same second
+	after, err := NewSession(userId, Grant{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	afterRefresh, err := NewRefreshToken(after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		claims  *Claims
+		revoked bool
+	}{
+		{"token of a session before", accessClaims(before), true},
+		{"token of a session after", accessClaims(after), false},
+		{"token without session", claimsAt(watermark), true},
+		{"token of a later second", claimsAt(watermark.Add(time.Second)), false},
+		{"token of an earlier second", claimsAt(watermark.Add(-time.Second)), true},
+	}
+
+	for _, test := range tests {
+		revoked, err := IsRevoked(test.claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revoked != test.revoked {
+			t.Errorf("[%s] got %v want %v", test.name, revoked, test.revoked)
+		}
+	}
+
+	if _, _, err = RotateRefreshToken(beforeRefresh, Grant{}); err != ErrRefreshTokenInvalid {
+		t.Errorf("refresh token before: got %v want %v", err, ErrRefreshTokenInvalid)
+	}
+	if _, _, err = RotateRefreshToken(afterRefresh, Grant{}); err != nil {
+		t.Errorf("refresh token after: got %v", err)
+	}
+}
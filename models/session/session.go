@@ -1,24 +1,32 @@
 package sessionModel
 
 import (
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
-	"github.com/handymesh/hyshAuthService/db/redis"
+	"github.com/handymesh/hyshAuthService/utils"
 )
 
 const (
-	ACCESS_TOKEN_DURATION  = time.Hour * 1
-	REFRESH_TOKEN_DURATION = time.Hour * 24 * 30 // 30 days
-	RECOVERY_LINK_DURATION = time.Hour * 1
+	AUTHORIZATION_CODE_DURATION = time.Minute * 1
+	DEVICE_CODE_DURATION        = time.Minute * 10
+	DEVICE_POLLING_INTERVAL     = time.Second * 5
 )
 
 var (
-	log        = logrus.New()
-	signingKey = []byte("your-secret-key")
+	log = logrus.New()
+
+	// Get configuration
+	ISSUER   = utils.Getenv("JWT_ISSUER", "http://localhost:4070")
+	AUDIENCE = utils.Getenv("JWT_AUDIENCE", "hysh")
+
+	// Space-separated clients allowed to sign users in through /auth
+	FIRST_PARTY_CLIENT_IDS = strings.Fields(utils.Getenv("FIRST_PARTY_CLIENT_IDS", "hysh-web"))
 )
 
 func init() {
@@ -29,87 +37,68 @@ func init() {
 	log.Formatter = new(logrus.JSONFormatter)
 }
 
-func NewAccessToken(timeDuration int64) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["exp"] = time.Now().Add(ACCESS_TOKEN_DURATION).Unix()
-	claims["iat"] = time.Now().Unix()
-	tokenString, err := token.SignedString(signingKey)
-	if err != nil {
-		return "", err
+// IsFirstParty reports whether the client is one of FIRST_PARTY_CLIENT_IDS.
+// The audience of access tokens is never a client.
+func IsFirstParty(clientId string) bool {
+	if clientId == "" || clientId == AUDIENCE {
+		return false
 	}
-
-	return tokenString, nil
+	for _, id := range FIRST_PARTY_CLIENT_IDS {
+		if id == clientId {
+			return true
+		}
+	}
+	return false
 }
 
-func NewRefreshToken(timeDuration time.Duration) (string, error) {
-	refreshToken, err := uuid.NewRandom()
-	if err != nil {
-		return "", err
+// NewAccessToken signs an access token for the claims. The token id,
+// issuer, audience and validity period are set here. The lifetime is the
+// one of the client, an expiry already set in the claims is kept if earlier.
+func NewAccessToken(claims *Claims) (string, error) {
+	signingKey := activeKey()
+	if signingKey == nil {
+		return "", errors.New("signing key is not loaded")
 	}
 
-	err = redis.Redis.Set(refreshToken.String(), "true", REFRESH_TOKEN_DURATION).Err()
+	jti, err := uuid.NewRandom()
 	if err != nil {
 		return "", err
 	}
 
-	return refreshToken.String(), nil
-}
-
-func NewRecoveryLink(value string) (string, error) {
-	refreshToken, err := uuid.NewRandom()
-	if err != nil {
-		return "", err
+	now := time.Now()
+	claims.Id = jti.String()
+	claims.Issuer = ISSUER
+	claims.Audience = AUDIENCE
+	claims.IssuedAt = now.Unix()
+	if expiresAt := now.Add(ClientLifetimes(claims.ClientID).AccessToken).Unix(); claims.ExpiresAt == 0 || claims.ExpiresAt > expiresAt {
+		claims.ExpiresAt = expiresAt
 	}
 
-	err = redis.Redis.Set(refreshToken.String(), value, RECOVERY_LINK_DURATION).Err()
+	token := jwt.NewWithClaims(signingKey.Method, claims)
+	token.Header["kid"] = signingKey.Kid
+	tokenString, err := token.SignedString(signingKey.PrivateKey)
 	if err != nil {
 		return "", err
 	}
 
-	return refreshToken.String(), nil
+	return tokenString, nil
 }
 
-func Delete(token string) error {
-	err := redis.Redis.Del(token).Err()
+// ParseAccessToken validates an access token and returns its claims
+// unless the token was revoked
+func ParseAccessToken(tokenString string) (*Claims, error) {
+	claims, err := AccessTokens.Validate(tokenString)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
-}
-
-func VerifyToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return signingKey, nil
-	})
-
-	return token, err
-}
 
-func CheckRefreshToken(token string) (bool, error) {
-	value := redis.Redis.Get(token)
-	if value.Err() != nil {
-		return false, value.Err()
-	}
-
-	status, err := value.Result()
-	if err != nil && status != "true" {
-		return false, err
-	}
-
-	return true, nil
-}
-
-func GetValueByKey(token string) (string, error) {
-	value := redis.Redis.Get(token)
-	if value.Err() != nil {
-		return "", value.Err()
+	revoked, err := IsRevoked(claims)
+	if err != nil {
+		return nil, err
 	}
-
-	status, err := value.Result()
-	if err != nil && status != "true" {
-		return "", err
+	if revoked {
+		return nil, ErrTokenRevoked
 	}
 
-	return status, nil
+	return claims, nil
 }
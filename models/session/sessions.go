@@ -0,0 +1,196 @@
+package sessionModel
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+)
+
+const (
+	USER_SESSIONS_PREFIX   = "sessions:user:"
+	REVOKED_SESSION_PREFIX = "revoked:sid:"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// NewSession starts a session for the user on the device of the grant
+func NewSession(userId string, grant Grant) (*Session, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	authTime := grant.AuthTime
+	if authTime.IsZero() {
+		authTime = now
+	}
+
+	lifetimes := ClientLifetimes(grant.ClientID)
+	session := &Session{
+		ID:          id.String(),
+		UserID:      userId,
+		ClientID:    grant.ClientID,
+		Scope:       grant.Scope,
+		Device:      grant.Device,
+		UserAgent:   grant.UserAgent,
+		IP:          grant.IP,
+		AuthTime:    authTime,
+		CreatedAt:   now,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(lifetimes.RefreshAbsolute),
+		IdleTimeout: int64(lifetimes.RefreshIdle.Seconds()),
+		JKT:         grant.JKT,
+	}
+
+	err = saveSession(session)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index sessions by user for listing, until the last one expires
+	key := USER_SESSIONS_PREFIX + userId
+	err = redis.Redis.SAdd(key, session.ID).Err()
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := redis.Redis.TTL(key).Result()
+	if err == nil && ttl < lifetimes.RefreshAbsolute {
+		err = redis.Redis.Expire(key, lifetimes.RefreshAbsolute).Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetSession returns a live session by id
+func GetSession(id string) (*Session, error) {
+	var session Session
+	err := Tokens.Get(PurposeSession, id, &session)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	return &session, nil
+}
+
+// ListSessions returns the live sessions of the user, most recently used first
+func ListSessions(userId string) ([]Session, error) {
+	key := USER_SESSIONS_PREFIX + userId
+	ids, err := redis.Redis.SMembers(key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []Session{}
+	for _, id := range ids {
+		session, err := GetSession(id)
+		if err != nil {
+			// expired or revoked
+			redis.Redis.SRem(key, id)
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	return sessions, nil
+}
+
+// RevokeSession ends the session of the user: its refresh tokens stop working
+// and access tokens issued for it are rejected
+func RevokeSession(userId string, id string) error {
+	session, err := GetSession(id)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userId {
+		return ErrSessionNotFound
+	}
+
+	return revokeSession(session)
+}
+
+// RevokeOtherSessions ends every session of the user except the current one
+func RevokeOtherSessions(userId string, currentId string) error {
+	sessions, err := ListSessions(userId)
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		if sessions[i].ID == currentId {
+			continue
+		}
+
+		err = revokeSession(&sessions[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func revokeSession(session *Session) error {
+	err := Tokens.Delete(PurposeSession, session.ID)
+	if err != nil {
+		return err
+	}
+
+	redis.Redis.SRem(USER_SESSIONS_PREFIX+session.UserID, session.ID)
+
+	// Access tokens of the session are rejected until they expire
+	ttl := ClientLifetimes(session.ClientID).AccessToken
+	return redis.Redis.Set(REVOKED_SESSION_PREFIX+session.ID, "true", ttl).Err()
+}
+
+// touchSession records a use of the session from the device of the grant
+func touchSession(session *Session, grant Grant) error {
+	session.LastUsedAt = time.Now()
+	session.UserAgent = grant.UserAgent
+	session.IP = grant.IP
+	if grant.Device != "" {
+		session.Device = grant.Device
+	}
+
+	return saveSession(session)
+}
+
+// Expiry is when the session and its refresh token end:
+// after the idle timeout since the last use, or at the absolute expiry
+func (s *Session) Expiry() time.Time {
+	expiry := s.ExpiresAt
+	if expiry.IsZero() {
+		// Sessions started before lifetimes were stored
+		expiry = s.CreatedAt.Add(REFRESH_TOKEN_ABSOLUTE_DURATION)
+	}
+
+	if s.IdleTimeout > 0 {
+		idle := s.LastUsedAt.Add(time.Duration(s.IdleTimeout) * time.Second)
+		if idle.Before(expiry) {
+			return idle
+		}
+	}
+
+	return expiry
+}
+
+// saveSession stores the session until it expires
+func saveSession(session *Session) error {
+	ttl := time.Until(session.Expiry())
+	if ttl <= 0 {
+		return ErrSessionNotFound
+	}
+
+	return Tokens.Put(PurposeSession, session.ID, session, ttl)
+}
@@ -0,0 +1,195 @@
+package sessionModel
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	goredis "github.com/go-redis/redis"
+
+	"github.com/handymesh/hyshAuthService/db/redis"
+)
+
+// Purpose namespaces the records kept in a TokenStore
+type Purpose string
+
+const (
+	PurposeRefresh         Purpose = "refresh"
+	PurposeRefreshConsumed Purpose = "refresh_consumed"
+	PurposeSession         Purpose = "session"
+	PurposeRecovery        Purpose = "recovery"
+	PurposeRecoveryRequest Purpose = "recovery_request"
+	PurposeAuthorization   Purpose = "authorization_code"
+	PurposeInitialAccess   Purpose = "initial_access"
+	PurposeDeviceCode      Purpose = "device_code"
+	PurposeUserCode        Purpose = "user_code"
+	PurposeDevicePoll      Purpose = "device_poll"
+	PurposeDeviceInterval  Purpose = "device_interval"
+	PurposeUserCodeEntry   Purpose = "user_code_entry"
+)
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+	ErrTokenPurpose  = errors.New("token purpose mismatch")
+)
+
+// TokenStore keeps short-lived token records. Records are typed by purpose
+// and a token issued for one purpose is never found under another.
+type TokenStore interface {
+	// Put stores the payload for the token
+	Put(purpose Purpose, token string, payload interface{}, ttl time.Duration) error
+	// PutNX stores the payload only if the token has no record yet
+	PutNX(purpose Purpose, token string, payload interface{}, ttl time.Duration) (bool, error)
+	// Get decodes the record of the token into payload
+	Get(purpose Purpose, token string, payload interface{}) error
+	// Consume reads and deletes the record in one step, for single-use tokens
+	Consume(purpose Purpose, token string, payload interface{}) error
+	// Exists reports whether the token has a record
+	Exists(purpose Purpose, token string) (bool, error)
+	// Delete removes the record of the token
+	Delete(purpose Purpose, token string) error
+	// Incr counts a use of the token and returns the count,
+	// the counter expires ttl after the first use
+	Incr(purpose Purpose, token string, ttl time.Duration) (int64, error)
+}
+
+// Tokens is the store used by the session model
+var Tokens TokenStore = &RedisTokenStore{Prefix: "token"}
+
+// envelope is the JSON stored in Redis, the purpose is checked on every read
+type envelope struct {
+	Purpose Purpose         `json:"purpose"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// RedisTokenStore keeps records under <Prefix>:<purpose>:<sha256(token)>,
+// so a Redis dump does not contain usable tokens
+type RedisTokenStore struct {
+	Prefix string
+}
+
+func (s *RedisTokenStore) Put(purpose Purpose, token string, payload interface{}, ttl time.Duration) error {
+	value, err := encodeEnvelope(purpose, payload)
+	if err != nil {
+		return err
+	}
+
+	return redis.Redis.Set(s.key(purpose, token), value, ttl).Err()
+}
+
+func (s *RedisTokenStore) PutNX(purpose Purpose, token string, payload interface{}, ttl time.Duration) (bool, error) {
+	value, err := encodeEnvelope(purpose, payload)
+	if err != nil {
+		return false, err
+	}
+
+	return redis.Redis.SetNX(s.key(purpose, token), value, ttl).Result()
+}
+
+func (s *RedisTokenStore) Get(purpose Purpose, token string, payload interface{}) error {
+	value, err := redis.Redis.Get(s.key(purpose, token)).Bytes()
+	if err == goredis.Nil {
+		return ErrTokenNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return decodeEnvelope(purpose, value, payload)
+}
+
+func (s *RedisTokenStore) Consume(purpose Purpose, token string, payload interface{}) error {
+	key := s.key(purpose, token)
+
+	var get *goredis.StringCmd
+	_, err := redis.Redis.TxPipelined(func(pipe goredis.Pipeliner) error {
+		get = pipe.Get(key)
+		pipe.Del(key)
+		return nil
+	})
+	if err == goredis.Nil {
+		return ErrTokenNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	value, err := get.Bytes()
+	if err != nil {
+		return err
+	}
+
+	return decodeEnvelope(purpose, value, payload)
+}
+
+func (s *RedisTokenStore) Exists(purpose Purpose, token string) (bool, error) {
+	count, err := redis.Redis.Exists(s.key(purpose, token)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *RedisTokenStore) Delete(purpose Purpose, token string) error {
+	return redis.Redis.Del(s.key(purpose, token)).Err()
+}
+
+func (s *RedisTokenStore) Incr(purpose Purpose, token string, ttl time.Duration) (int64, error) {
+	key := s.key(purpose, token)
+
+	var incr *goredis.IntCmd
+	_, err := redis.Redis.TxPipelined(func(pipe goredis.Pipeliner) error {
+		pipe.SetNX(key, 0, ttl)
+		incr = pipe.Incr(key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (s *RedisTokenStore) key(purpose Purpose, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return s.Prefix + ":" + string(purpose) + ":" + hex.EncodeToString(sum[:])
+}
+
+// NewOpaqueToken returns a random URL-safe token with 256 bits of entropy
+func NewOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func encodeEnvelope(purpose Purpose, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(envelope{Purpose: purpose, Data: data})
+}
+
+func decodeEnvelope(purpose Purpose, value []byte, payload interface{}) error {
+	var e envelope
+	err := json.Unmarshal(value, &e)
+	if err != nil {
+		return err
+	}
+
+	if e.Purpose != purpose {
+		return ErrTokenPurpose
+	}
+
+	return json.Unmarshal(e.Data, payload)
+}
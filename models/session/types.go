@@ -1,7 +1,86 @@
 package sessionModel
 
-// Session model
+import "time"
+
+// Session is a signed-in device of a user. Refresh tokens rotated
+// from one login belong to the same session.
 type Session struct {
-	token  string
-	status bool
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	ClientID   string    `json:"client_id"`
+	Scope      string    `json:"scope"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	AuthTime   time.Time `json:"auth_time"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+
+	// Absolute expiry, and the idle timeout in seconds, of the client lifetimes
+	ExpiresAt   time.Time `json:"expires_at"`
+	IdleTimeout int64     `json:"idle_timeout"`
+
+	// Thumbprint of the DPoP key the refresh tokens are bound to
+	JKT string `json:"jkt,omitempty"`
+}
+
+// Grant describes who tokens are issued to and where the request came from
+type Grant struct {
+	ClientID  string
+	Scope     string
+	Nonce     string
+	AuthTime  time.Time
+	Device    string
+	UserAgent string
+	IP        string
+	// Thumbprint of the DPoP key of the client, if it sent a proof
+	JKT string
+}
+
+// RefreshToken is the record stored for an issued refresh token
+type RefreshToken struct {
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	ClientID  string    `json:"client_id"`
+	IssuedAt  time.Time `json:"issued_at"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+}
+
+// RecoveryLink is the record stored for a password recovery link
+type RecoveryLink struct {
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuthorizationCode is the record stored for an issued authorization code
+type AuthorizationCode struct {
+	ClientID            string    `json:"client_id"`
+	RedirectURI         string    `json:"redirect_uri"`
+	UserID              string    `json:"user_id"`
+	Scope               string    `json:"scope"`
+	Nonce               string    `json:"nonce"`
+	CodeChallenge       string    `json:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method"`
+	AuthTime            time.Time `json:"auth_time"`
+}
+
+// DeviceAuthorization is a pending device authorization request (RFC 8628).
+// UserID and AuthTime are set once the user approves it.
+type DeviceAuthorization struct {
+	ClientID  string    `json:"client_id"`
+	Scope     string    `json:"scope"`
+	UserCode  string    `json:"user_code"`
+	Status    string    `json:"status"`
+	UserID    string    `json:"user_id,omitempty"`
+	AuthTime  time.Time `json:"auth_time,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Interval is the polling interval, raised on every slow_down
+	Interval time.Duration `json:"interval"`
+}
+
+// InitialAccessToken allows a single dynamic client registration
+type InitialAccessToken struct {
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -0,0 +1,113 @@
+package sessionModel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	// Get configuration
+	JWT_LEEWAY = durationEnv("JWT_LEEWAY", "30s")
+
+	ErrTokenMalformed   = errors.New("token malformed")
+	ErrTokenUnknownKey  = errors.New("token signing key unknown")
+	ErrTokenAlgorithm   = errors.New("token algorithm not allowed")
+	ErrTokenSignature   = errors.New("token signature invalid")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrTokenIssuer      = errors.New("token issuer invalid")
+	ErrTokenAudience    = errors.New("token audience invalid")
+	ErrTokenSubject     = errors.New("token subject missing")
+)
+
+// Validator verifies access tokens. Each signing key only accepts its own
+// algorithm, exp is required, and exp, nbf and iat are checked with Leeway
+// for the clock skew between replicas.
+type Validator struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+	// Key returns the signing key of a kid, or nil
+	Key func(kid string) *SigningKey
+	Now func() time.Time
+}
+
+// AccessTokens validates the access tokens of this issuer against the key ring
+var AccessTokens = &Validator{
+	Issuer:   ISSUER,
+	Audience: AUDIENCE,
+	Leeway:   JWT_LEEWAY,
+	Key:      ringKey,
+	Now:      time.Now,
+}
+
+// Validate verifies the signature and the registered claims of the token
+func (v *Validator) Validate(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	// Time based claims are checked below with the leeway
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(tokenString, claims, v.keyFunc)
+	if err != nil {
+		return nil, validationError(err)
+	}
+
+	now := v.Now().Unix()
+	leeway := int64(v.Leeway.Seconds())
+	if claims.ExpiresAt == 0 || now > claims.ExpiresAt+leeway {
+		return nil, ErrTokenExpired
+	}
+	if now < claims.NotBefore-leeway || now < claims.IssuedAt-leeway {
+		return nil, ErrTokenNotYetValid
+	}
+
+	if v.Issuer == "" || claims.Issuer != v.Issuer {
+		return nil, ErrTokenIssuer
+	}
+	if v.Audience == "" || claims.Audience != v.Audience {
+		return nil, ErrTokenAudience
+	}
+	if claims.Subject == "" {
+		return nil, ErrTokenSubject
+	}
+
+	return claims, nil
+}
+
+// keyFunc pins the algorithm of the token to the one of its signing key
+func (v *Validator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key := v.Key(kid)
+	if key == nil {
+		return nil, ErrTokenUnknownKey
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrTokenAlgorithm
+	}
+
+	return key.PublicKey(), nil
+}
+
+// validationError maps the errors of jwt-go to the typed errors
+func validationError(err error) error {
+	validationErr, ok := err.(*jwt.ValidationError)
+	if !ok {
+		return ErrTokenMalformed
+	}
+
+	switch {
+	case validationErr.Inner == ErrTokenUnknownKey || validationErr.Inner == ErrTokenAlgorithm:
+		return validationErr.Inner
+	case validationErr.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+		return ErrTokenSignature
+	case validationErr.Errors&jwt.ValidationErrorUnverifiable != 0:
+		// jwt-go rejects the alg header before the key is resolved
+		return ErrTokenAlgorithm
+	}
+
+	return ErrTokenMalformed
+}
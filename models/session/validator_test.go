@@ -0,0 +1,121 @@
+package sessionModel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestValidator(t *testing.T) {
+	key, err := NewSigningKey("ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := NewSigningKey("ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	validator := &Validator{
+		Issuer:   "https://auth.test",
+		Audience: "hysh",
+		Leeway:   time.Second * 30,
+		Key: func(kid string) *SigningKey {
+			if kid == key.Kid {
+				return key
+			}
+			return nil
+		},
+		Now: func() time.Time { return now },
+	}
+
+	sign := func(signingKey *SigningKey, kid string, edit func(*Claims)) string {
+		claims := &Claims{StandardClaims: jwt.StandardClaims{
+			Subject:   "5c3a1b2e9d4f6a7b8c9d0e1f",
+			Issuer:    "https://auth.test",
+			Audience:  "hysh",
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Hour).Unix(),
+		}}
+		if edit != nil {
+			edit(claims)
+		}
+
+		token := jwt.NewWithClaims(signingKey.Method, claims)
+		token.Header["kid"] = kid
+		tokenString, err := token.SignedString(signingKey.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokenString
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", sign(key, key.Kid, nil), nil},
+		{"expired within leeway", sign(key, key.Kid, func(c *Claims) { c.ExpiresAt = now.Add(-time.Second * 10).Unix() }), nil},
+		{"expired", sign(key, key.Kid, func(c *Claims) { c.ExpiresAt = now.Add(-time.Minute).Unix() }), ErrTokenExpired},
+		{"without exp", sign(key, key.Kid, func(c *Claims) { c.ExpiresAt = 0 }), ErrTokenExpired},
+		{"not yet valid", sign(key, key.Kid, func(c *Claims) { c.NotBefore = now.Add(time.Minute).Unix() }), ErrTokenNotYetValid},
+		{"issued in the future", sign(key, key.Kid, func(c *Claims) { c.IssuedAt = now.Add(time.Minute).Unix() }), ErrTokenNotYetValid},
+		{"wrong issuer", sign(key, key.Kid, func(c *Claims) { c.Issuer = "https://other.test" }), ErrTokenIssuer},
+		{"wrong audience", sign(key, key.Kid, func(c *Claims) { c.Audience = "other" }), ErrTokenAudience},
+		{"without subject", sign(key, key.Kid, func(c *Claims) { c.Subject = "" }), ErrTokenSubject},
+		{"unknown key", sign(otherKey, otherKey.Kid, nil), ErrTokenUnknownKey},
+		{"bad signature", sign(otherKey, key.Kid, nil), ErrTokenSignature},
+		{"malformed", "not.a.token", ErrTokenMalformed},
+	}
+
+	for _, test := range tests {
+		_, err := validator.Validate(test.token)
+		if err != test.err {
+			t.Errorf("[%s] got %v want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestValidatorAlgorithmPinning(t *testing.T) {
+	key, err := NewSigningKey("ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := NewSigningKey("RS256")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := &Validator{
+		Issuer:   ISSUER,
+		Audience: AUDIENCE,
+		Key:      func(kid string) *SigningKey { return key },
+		Now:      time.Now,
+	}
+
+	// An RS256 token must not verify against an ES256 key, whatever the kid
+	token := jwt.NewWithClaims(rsaKey.Method, &Claims{StandardClaims: jwt.StandardClaims{
+		Subject:   "5c3a1b2e9d4f6a7b8c9d0e1f",
+		Issuer:    ISSUER,
+		Audience:  AUDIENCE,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}})
+	token.Header["kid"] = key.Kid
+	tokenString, err := token.SignedString(rsaKey.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = validator.Validate(tokenString); err != ErrTokenAlgorithm {
+		t.Errorf("[alg] got %v want %v", err, ErrTokenAlgorithm)
+	}
+
+	// alg "none" is never accepted
+	none := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiJ4In0."
+	if _, err = validator.Validate(none); err != ErrTokenAlgorithm {
+		t.Errorf("[none] got %v want %v", err, ErrTokenAlgorithm)
+	}
+}
@@ -8,9 +8,12 @@ import (
 type User struct {
 	Id            string     `json:"id" bson:"_id,omitempty"`
 	Email         *string    `json:"email" bson:"email,omitempty"`
+	EmailVerified bool       `json:"email_verified" bson:"email_verified,omitempty"`
 	Locale        string     `json:"locale" bson:"locale,omitempty"`
 	Fullname      string     `json:"fullname" bson:"fullname,omitempty"`
 	Password      string     `json:"password" bson:"password,omitempty"`
+	Roles         []string   `json:"roles" bson:"roles,omitempty"`
+	Status        string     `json:"status" bson:"status,omitempty"`
 	PasswordRetry string     `json:"retryPassword" bson:"-"`
 	RecoveryToken string     `json:"recoveryToken" bson:"-"`
 	Gender        string     `json:"gender" bson:"-"`
@@ -24,6 +27,8 @@ type UserResponse struct {
 	Email     *string   `json:"email"`
 	Fullname  string    `json:"fullname"`
 	Gender    string    `json:"gender"`
+	Roles     []string  `json:"roles"`
+	Status    string    `json:"status"`
 	Profiles  []Profile `json:"profiles"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -36,3 +41,23 @@ type Profile struct {
 	Name    string `json:"name"`
 	Picture string `json:"picture"`
 }
+
+// GetRoles returns the user roles, users created before roles existed are plain users
+func (u *User) GetRoles() []string {
+	if len(u.Roles) == 0 {
+		return []string{ROLE_USER}
+	}
+	return u.Roles
+}
+
+// IsActive reports whether the user may sign in
+func (u *User) IsActive() bool {
+	return u.Status != USER_STATUS_SUSPENDED
+}
+
+// StripPrivileged drops the fields only admins may set
+func (u *User) StripPrivileged() {
+	u.Roles = nil
+	u.Status = ""
+	u.EmailVerified = false
+}
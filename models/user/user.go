@@ -17,11 +17,20 @@ import (
 const (
 	// CollectionUser holds the name of the articles collection
 	CollectionUser = "users"
+
+	ROLE_USER  = "user"
+	ROLE_ADMIN = "admin"
+
+	// ROLE_SUPPORT may impersonate customers via token exchange
+	ROLE_SUPPORT = "support"
+
+	USER_STATUS_ACTIVE    = "active"
+	USER_STATUS_SUSPENDED = "suspended"
 )
 
 func List() (error, []User) {
 	var users []User
-	opts := options.Find().SetSort(bson.D{{"created_at", 1}})
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
 	cursor, err := mongodb.Session.Database("auth").Collection(CollectionUser).Find(nil, bson.D{}, opts)
 	if err != nil {
 		return err, nil
@@ -64,6 +73,49 @@ func FindOne(user User) (*User, error) {
 	return result, nil
 }
 
+func FindByID(userId string) (*User, error) {
+	id, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *User
+	err = mongodb.Session.Database("auth").Collection(CollectionUser).FindOne(nil, bson.M{"_id": id}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindByIDs returns the users with the given ids, ids that are not valid ObjectIDs are skipped
+func FindByIDs(userIds []string) ([]User, error) {
+	ids := make([]primitive.ObjectID, 0, len(userIds))
+	for _, userId := range userIds {
+		id, err := primitive.ObjectIDFromHex(userId)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	users := []User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	cursor, err := mongodb.Session.Database("auth").Collection(CollectionUser).Find(nil, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(nil, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func FindCount(user User) (int64, error) {
 	count, err := mongodb.Session.Database("auth").Collection(CollectionUser).CountDocuments(nil, user)
 
@@ -90,7 +142,12 @@ func Add(user User) (error, User) {
 	}
 
 	user.Password, _ = crypto.HashPassword(user.Password)
-	fmt.Printf("User password %v", user.Password)
+	if len(user.Roles) == 0 {
+		user.Roles = []string{ROLE_USER}
+	}
+	if user.Status == "" {
+		user.Status = USER_STATUS_ACTIVE
+	}
 	time := time.Now()
 	user.CreatedAt = &time
 	user.UpdatedAt = &time
@@ -105,36 +162,67 @@ func Add(user User) (error, User) {
 	return nil, user
 }
 
+// Update sets the fields of the user. A changed email has to be verified again.
 func Update(user *User) (*User, error) {
+	id, err := primitive.ObjectIDFromHex(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentEmail *string
+	if user.Email != nil {
+		current, err := FindByID(user.Id)
+		if err != nil {
+			return nil, errors.New(`{"Id":"User not exists"}`)
+		}
+		currentEmail = current.Email
+	}
+
 	UpdatedAt := time.Now()
 	user.UpdatedAt = &UpdatedAt
 	user.Id = "" // prohibit changing address
 
 	opts := options.Update().SetUpsert(false)
-	filter := User{Email: user.Email}
-	fmt.Println("User is", user)
-	update := bson.D{
-		{"$set", user},
-	}
+	filter := bson.M{"_id": id}
+	update := updateDocument(user, currentEmail)
 	result, err := mongodb.Session.Database("auth").Collection(CollectionUser).UpdateOne(nil, filter, update, opts)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("result", result)
+	if result.MatchedCount == 0 {
+		return nil, errors.New(`{"Id":"User not exists"}`)
+	}
 
-	if result.MatchedCount != 0 {
-		fmt.Println("matched and replaced an existing document")
+	user.Id = id.Hex()
+	return user, nil
+}
+
+// updateDocument sets the fields of the user. email_verified is left out of
+// the $set when false, so it is unset when the email changes without being verified.
+func updateDocument(user *User, currentEmail *string) bson.D {
+	update := bson.D{
+		{Key: "$set", Value: user},
 	}
-	if result.UpsertedCount != 0 {
-		fmt.Printf("inserted a new document with ID %v\n", result.UpsertedID)
+
+	emailChanged := user.Email != nil && (currentEmail == nil || *currentEmail != *user.Email)
+	if emailChanged && !user.EmailVerified {
+		update = append(update, bson.E{Key: "$unset", Value: bson.M{"email_verified": ""}})
 	}
 
-	return user, nil
+	return update
 }
 
 func Delete(userId string) (int64, error) {
-	filter := bson.D{{"_id", userId}}
+	id, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.M{"_id": id}
 	res, err := mongodb.Session.Database("auth").Collection(CollectionUser).DeleteOne(nil, filter)
+	if err != nil {
+		return 0, err
+	}
 
-	return res.DeletedCount, err
+	return res.DeletedCount, nil
 }
@@ -0,0 +1,45 @@
+package userModel
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestUpdateDocumentUnverifiesChangedEmail(t *testing.T) {
+	email := func(value string) *string { return &value }
+
+	tests := []struct {
+		name         string
+		user         User
+		currentEmail *string
+		unverified   bool
+	}{
+		{"changed email", User{Email: email("new@hysh.test")}, email("old@hysh.test"), true},
+		{"first email", User{Email: email("new@hysh.test")}, nil, true},
+		{"same email", User{Email: email("old@hysh.test")}, email("old@hysh.test"), false},
+		{"without email", User{Fullname: "Name"}, email("old@hysh.test"), false},
+		{"changed email verified by an admin", User{Email: email("new@hysh.test"), EmailVerified: true}, email("old@hysh.test"), false},
+	}
+
+	for _, test := range tests {
+		user := test.user
+		update := updateDocument(&user, test.currentEmail)
+
+		// The $set must not keep a verified flag for the new email
+		set, err := bson.Marshal(update.Map()["$set"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		verified, err := bson.Raw(set).LookupErr("email_verified")
+		if err == nil && verified.Boolean() && test.unverified {
+			t.Errorf("[%s] $set keeps email_verified", test.name)
+		}
+
+		fields, _ := update.Map()["$unset"].(bson.M)
+		_, unset := fields["email_verified"]
+		if unset != test.unverified {
+			t.Errorf("[%s] got unset %v want %v", test.name, unset, test.unverified)
+		}
+	}
+}
@@ -60,3 +60,10 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Create the name of the secret holding the encryption keys
+*/}}
+{{- define "hyshauthservice.encryptionSecretName" -}}
+{{- default (printf "%s-encryption" (include "hyshauthservice.fullname" .)) .Values.encryption.existingSecret }}
+{{- end }}
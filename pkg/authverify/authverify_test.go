@@ -0,0 +1,238 @@
+package authverify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type testKey struct {
+	kid        string
+	privateKey *ecdsa.PrivateKey
+	jwk        jwk
+}
+
+func newTestKey(t *testing.T) *testKey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	kid := base64.RawURLEncoding.EncodeToString(b)
+
+	return &testKey{kid: kid, privateKey: privateKey, jwk: jwk{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Alg: "ES256",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(privateKey.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(privateKey.Y.FillBytes(make([]byte, 32))),
+	}}
+}
+
+func (k *testKey) sign(t *testing.T, edit func(jwt.MapClaims)) string {
+	claims := jwt.MapClaims{
+		"sub":   "5c3a1b2e9d4f6a7b8c9d0e1f",
+		"iss":   "https://auth.test",
+		"aud":   "hysh",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "users:read users:write",
+		"roles": []string{"user"},
+	}
+	if edit != nil {
+		edit(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = k.kid
+	tokenString, err := token.SignedString(k.privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenString
+}
+
+// jwksServer serves the public part of the keys, which can be replaced to simulate a rotation
+type jwksServer struct {
+	sync.Mutex
+	*httptest.Server
+	keys []jwk
+}
+
+func newJWKSServer(keys ...*testKey) *jwksServer {
+	s := &jwksServer{}
+	s.set(keys...)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+		json.NewEncoder(w).Encode(jwks{Keys: s.keys})
+	}))
+	return s
+}
+
+func (s *jwksServer) set(keys ...*testKey) {
+	s.Lock()
+	defer s.Unlock()
+	s.keys = nil
+	for _, key := range keys {
+		s.keys = append(s.keys, key.jwk)
+	}
+}
+
+func newTestVerifier(t *testing.T, server *jwksServer) *Verifier {
+	v, err := New(Config{
+		JWKSURL:  server.URL,
+		Issuer:   "https://auth.test",
+		Audience: "hysh",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(v.Close)
+	return v
+}
+
+func TestVerify(t *testing.T) {
+	key := newTestKey(t)
+	server := newJWKSServer(key)
+	defer server.Close()
+	v := newTestVerifier(t, server)
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", key.sign(t, nil), nil},
+		{"audience array", key.sign(t, func(c jwt.MapClaims) { c["aud"] = []string{"other", "hysh"} }), nil},
+		{"expired", key.sign(t, func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }), ErrTokenExpired},
+		{"wrong issuer", key.sign(t, func(c jwt.MapClaims) { c["iss"] = "https://other.test" }), ErrTokenIssuer},
+		{"wrong audience", key.sign(t, func(c jwt.MapClaims) { c["aud"] = "other" }), ErrTokenAudience},
+		{"bound", key.sign(t, func(c jwt.MapClaims) { c["cnf"] = map[string]string{"jkt": "thumbprint"} }), ErrTokenBound},
+		{"unknown key", newTestKey(t).sign(t, nil), ErrTokenUnknownKey},
+		{"missing", "", ErrTokenMissing},
+		{"malformed", "not.a.token", ErrTokenMalformed},
+	}
+
+	for _, test := range tests {
+		_, err := v.Verify(test.token)
+		if err != test.err {
+			t.Errorf("[%s] got %v want %v", test.name, err, test.err)
+		}
+	}
+
+	principal, err := v.Verify(key.sign(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.SubjectType != SUBJECT_TYPE_USER || !principal.HasScopes("users:read", "users:write") || !principal.HasRole("user") {
+		t.Errorf("unexpected principal %+v", principal)
+	}
+}
+
+func TestVerifyRotatedKey(t *testing.T) {
+	key := newTestKey(t)
+	server := newJWKSServer(key)
+	defer server.Close()
+	v := newTestVerifier(t, server)
+
+	rotated := newTestKey(t)
+	server.set(key, rotated)
+
+	// The keys were just fetched, an unknown kid waits for the cooldown
+	v.keys.fetchedAt = time.Now().Add(-KEY_REFRESH_COOLDOWN * 2)
+
+	if _, err := v.Verify(rotated.sign(t, nil)); err != nil {
+		t.Errorf("rotated key: %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	key := newTestKey(t)
+	server := newJWKSServer(key)
+	defer server.Close()
+	v := newTestVerifier(t, server)
+
+	handler := v.Middleware(RequireScope("users:read")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if FromContext(r.Context()) == nil {
+			t.Error("principal missing from context")
+		}
+	})))
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"valid", key.sign(t, nil), http.StatusOK},
+		{"missing", "", http.StatusUnauthorized},
+		{"insufficient scope", key.sign(t, func(c jwt.MapClaims) { c["scope"] = "openid" }), http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.token != "" {
+			r.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("[%s] got status %d want %d", test.name, w.Code, test.status)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	key := newTestKey(t)
+	server := newJWKSServer(key)
+	defer server.Close()
+	v := newTestVerifier(t, server)
+
+	interceptor := v.UnaryServerInterceptor("users:read")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return FromContext(ctx), nil
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		code  codes.Code
+	}{
+		{"valid", key.sign(t, nil), codes.OK},
+		{"missing", "", codes.Unauthenticated},
+		{"insufficient scope", key.sign(t, func(c jwt.MapClaims) { c["scope"] = "openid" }), codes.PermissionDenied},
+	}
+
+	for _, test := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+test.token))
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Test/Call"}, handler)
+
+		if code := status.Code(err); code != test.code {
+			t.Errorf("[%s] got code %v want %v", test.name, code, test.code)
+		}
+		if err == nil && resp.(*Principal) == nil {
+			t.Errorf("[%s] principal missing from context", test.name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package authverify
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor verifies the bearer token in the "authorization"
+// metadata and puts the caller into the context, see FromContext.
+// The token must grant all scopes.
+func (v *Verifier) UnaryServerInterceptor(scopes ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := v.authenticate(ctx, scopes)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming calls
+func (v *Verifier) StreamServerInterceptor(scopes ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := v.authenticate(ss.Context(), scopes)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// CheckScope returns a PermissionDenied error unless the caller
+// set by the interceptors was granted all scopes
+func CheckScope(ctx context.Context, scopes ...string) error {
+	principal := FromContext(ctx)
+	if principal == nil {
+		return status.Error(codes.Unauthenticated, ErrTokenMissing.Error())
+	}
+	if !principal.HasScopes(scopes...) {
+		return status.Error(codes.PermissionDenied, ErrInsufficientScope.Error())
+	}
+	return nil
+}
+
+func (v *Verifier) authenticate(ctx context.Context, scopes []string) (context.Context, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = bearerToken(values[0])
+		}
+	}
+
+	principal, err := v.Verify(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ctx = NewContext(ctx, principal)
+	return ctx, CheckScope(ctx, scopes...)
+}
+
+// serverStream replaces the context of a stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,93 @@
+package authverify
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Middleware verifies the bearer token of the request and puts
+// the caller into the request context, see FromContext
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := v.Verify(BearerToken(r))
+		if err != nil {
+			status := http.StatusUnauthorized
+			if err == ErrTokenMalformed {
+				status = http.StatusBadRequest
+			}
+
+			w.Header().Set("WWW-Authenticate", challenge(err))
+			writeError(w, err, status)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
+	})
+}
+
+// RequireScope rejects callers whose token doesn't grant all scopes.
+// It must run after Middleware.
+func RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := FromContext(r.Context())
+			if principal == nil {
+				w.Header().Set("WWW-Authenticate", challenge(ErrTokenMissing))
+				writeError(w, ErrTokenMissing, http.StatusUnauthorized)
+				return
+			}
+
+			if !principal.HasScopes(scopes...) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+				writeError(w, ErrInsufficientScope, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BearerToken reads the token from the Authorization header,
+// with or without the "Bearer" scheme
+func BearerToken(r *http.Request) string {
+	return bearerToken(r.Header.Get("Authorization"))
+}
+
+func bearerToken(authorization string) string {
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") {
+		return strings.TrimSpace(authorization[7:])
+	}
+	return authorization
+}
+
+// challenge is the WWW-Authenticate header for a failed verification (RFC 6750, section 3.1)
+func challenge(err error) string {
+	switch err {
+	case ErrTokenMissing:
+		return `Bearer realm="hysh"`
+	case ErrTokenMalformed:
+		return `Bearer error="invalid_request", error_description="` + err.Error() + `"`
+	}
+	return `Bearer error="invalid_token", error_description="` + err.Error() + `"`
+}
+
+// errorResponse is the error envelope of the auth service
+type errorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, err error, statusCode int) {
+	var response errorResponse
+	response.Error.Code = statusCode
+	// quoted like the messages of the auth service
+	response.Error.Message = `"` + err.Error() + `"`
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,150 @@
+package authverify
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// minimal pause between refreshes triggered by an unknown kid
+	KEY_REFRESH_COOLDOWN = time.Second * 5
+)
+
+// verificationKey is a parsed JWK with the only algorithm it accepts
+type verificationKey struct {
+	alg       string
+	publicKey crypto.PublicKey
+}
+
+// keySet caches the keys of the JWKS endpoint
+type keySet struct {
+	sync.RWMutex
+	url       string
+	client    *http.Client
+	keys      map[string]verificationKey
+	fetchedAt time.Time
+
+	// serializes the fetches
+	fetch sync.Mutex
+}
+
+func newKeySet(url string, client *http.Client) *keySet {
+	return &keySet{
+		url:    url,
+		client: client,
+		keys:   map[string]verificationKey{},
+	}
+}
+
+// key resolves a key by kid. An unknown kid refreshes the keys first,
+// the auth service may have rotated its signing key.
+func (s *keySet) key(kid string) (verificationKey, bool) {
+	s.RLock()
+	key, ok := s.keys[kid]
+	stale := time.Since(s.fetchedAt) > KEY_REFRESH_COOLDOWN
+	s.RUnlock()
+
+	if ok || !stale {
+		return key, ok
+	}
+
+	err := s.refresh()
+	if err != nil {
+		return key, false
+	}
+
+	s.RLock()
+	key, ok = s.keys[kid]
+	s.RUnlock()
+
+	return key, ok
+}
+
+// watch refreshes the keys every interval until done is closed.
+// The cached keys stay in use while the endpoint is unavailable.
+func (s *keySet) watch(interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+// refresh replaces the cached keys with the ones of the JWKS endpoint
+func (s *keySet) refresh() error {
+	s.fetch.Lock()
+	defer s.fetch.Unlock()
+
+	// Another caller has just fetched the keys
+	s.RLock()
+	fresh := time.Since(s.fetchedAt) < KEY_REFRESH_COOLDOWN
+	s.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("JWKS endpoint responded " + resp.Status)
+	}
+
+	var set jwks
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]verificationKey{}
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := key.publicKey()
+		if err != nil {
+			continue
+		}
+
+		alg := key.Alg
+		if alg == "" {
+			alg = defaultAlg(key)
+		}
+
+		keys[key.Kid] = verificationKey{alg: alg, publicKey: publicKey}
+	}
+
+	s.Lock()
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	s.Unlock()
+
+	return nil
+}
+
+// defaultAlg is the algorithm the auth service signs with for a key type
+func defaultAlg(key jwk) string {
+	switch key.Kty {
+	case "RSA":
+		return "RS256"
+	case "EC":
+		return "ES256"
+	case "OKP":
+		return "EdDSA"
+	}
+	return ""
+}
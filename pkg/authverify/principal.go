@@ -0,0 +1,100 @@
+package authverify
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	SUBJECT_TYPE_USER   = "user"
+	SUBJECT_TYPE_CLIENT = "client"
+)
+
+// Principal is the caller identified by an access token
+type Principal struct {
+	Subject     string
+	SubjectType string
+	SessionID   string
+	ClientID    string
+	Roles       []string
+	Scopes      []string
+	TokenID     string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	Actor       *Actor
+}
+
+// Actor is the party acting on behalf of the subject of a token obtained
+// by token exchange. Act holds the previous actor of a chain of exchanges.
+type Actor struct {
+	Subject     string `json:"sub"`
+	SubjectType string `json:"sub_type,omitempty"`
+	Act         *Actor `json:"act,omitempty"`
+}
+
+type principalCtxKey struct{}
+
+func newPrincipal(c *claims) *Principal {
+	subjectType := c.SubjectType
+	if subjectType == "" {
+		subjectType = SUBJECT_TYPE_USER
+	}
+
+	return &Principal{
+		Subject:     c.Subject,
+		SubjectType: subjectType,
+		SessionID:   c.SessionID,
+		ClientID:    c.ClientID,
+		Roles:       c.Roles,
+		Scopes:      strings.Fields(c.Scope),
+		TokenID:     c.Id,
+		IssuedAt:    time.Unix(c.IssuedAt, 0),
+		ExpiresAt:   time.Unix(c.ExpiresAt, 0),
+		Actor:       c.Act,
+	}
+}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, principal)
+}
+
+// FromContext returns the principal set by the middleware or the interceptors, or nil
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalCtxKey{}).(*Principal)
+	return principal
+}
+
+// IsClient reports whether the caller is a service authenticated with its client credentials
+func (p *Principal) IsClient() bool {
+	return p.SubjectType == SUBJECT_TYPE_CLIENT
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScopes reports whether all scopes are granted
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !p.HasScope(scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+// Package authverify verifies the access tokens of hyshAuthService in other
+// services. Tokens are checked offline against the keys published at the
+// JWKS endpoint, so a revoked token stays valid until it expires; use the
+// ValidateToken gRPC call where revocation must take effect immediately.
+// Tokens bound to a DPoP key are rejected.
+package authverify
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	ErrTokenMissing      = errors.New("token missing")
+	ErrTokenMalformed    = errors.New("token malformed")
+	ErrTokenUnknownKey   = errors.New("token signing key unknown")
+	ErrTokenAlgorithm    = errors.New("token algorithm not allowed")
+	ErrTokenSignature    = errors.New("token signature invalid")
+	ErrTokenExpired      = errors.New("token expired")
+	ErrTokenNotYetValid  = errors.New("token not yet valid")
+	ErrTokenIssuer       = errors.New("token issuer invalid")
+	ErrTokenAudience     = errors.New("token audience invalid")
+	ErrTokenSubject      = errors.New("token subject missing")
+	ErrTokenBound        = errors.New("token is bound to a DPoP key")
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// Config of a Verifier. JWKSURL, Issuer and Audience are required.
+type Config struct {
+	// JWKSURL is the JWKS endpoint, e.g. https://auth.example.com/.well-known/jwks.json
+	JWKSURL  string
+	Issuer   string
+	Audience string
+	// Leeway for the clock skew when checking exp, nbf and iat, 30s by default
+	Leeway time.Duration
+	// RefreshInterval of the keys in background, 5m by default
+	RefreshInterval time.Duration
+	// HTTPClient fetches the keys, a client with a 10s timeout by default
+	HTTPClient *http.Client
+}
+
+// Verifier verifies access tokens against the cached keys of the JWKS endpoint
+type Verifier struct {
+	config Config
+	keys   *keySet
+	now    func() time.Time
+	done   chan struct{}
+}
+
+// claims of an access token
+type claims struct {
+	Subject     string   `json:"sub"`
+	SubjectType string   `json:"sub_type,omitempty"`
+	Issuer      string   `json:"iss"`
+	Audience    audience `json:"aud"`
+	ExpiresAt   int64    `json:"exp"`
+	NotBefore   int64    `json:"nbf,omitempty"`
+	IssuedAt    int64    `json:"iat,omitempty"`
+	Id          string   `json:"jti,omitempty"`
+	SessionID   string   `json:"sid,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	Act         *Actor   `json:"act,omitempty"`
+	Cnf         *struct {
+		JKT string `json:"jkt"`
+	} `json:"cnf,omitempty"`
+}
+
+// Valid is a no-op, Verify checks the claims with the leeway
+func (c *claims) Valid() error {
+	return nil
+}
+
+// New fetches the keys and refreshes them in background until Close
+func New(config Config) (*Verifier, error) {
+	if config.JWKSURL == "" || config.Issuer == "" || config.Audience == "" {
+		return nil, errors.New("JWKSURL, Issuer and Audience are required")
+	}
+	if config.Leeway == 0 {
+		config.Leeway = time.Second * 30
+	}
+	if config.RefreshInterval == 0 {
+		config.RefreshInterval = time.Minute * 5
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: time.Second * 10}
+	}
+
+	v := &Verifier{
+		config: config,
+		keys:   newKeySet(config.JWKSURL, config.HTTPClient),
+		now:    time.Now,
+		done:   make(chan struct{}),
+	}
+
+	err := v.keys.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	go v.keys.watch(config.RefreshInterval, v.done)
+
+	return v, nil
+}
+
+// Close stops the background refresh of the keys
+func (v *Verifier) Close() {
+	close(v.done)
+}
+
+// Verify checks the signature and the claims of an access token
+func (v *Verifier) Verify(token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrTokenMissing
+	}
+
+	c := &claims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(token, c, v.keyFunc)
+	if err != nil {
+		return nil, validationError(err)
+	}
+
+	now := v.now().Unix()
+	leeway := int64(v.config.Leeway.Seconds())
+	if c.ExpiresAt == 0 || now > c.ExpiresAt+leeway {
+		return nil, ErrTokenExpired
+	}
+	if now < c.NotBefore-leeway || now < c.IssuedAt-leeway {
+		return nil, ErrTokenNotYetValid
+	}
+
+	if c.Issuer != v.config.Issuer {
+		return nil, ErrTokenIssuer
+	}
+	if !c.Audience.contains(v.config.Audience) {
+		return nil, ErrTokenAudience
+	}
+	if c.Subject == "" {
+		return nil, ErrTokenSubject
+	}
+	if c.Cnf != nil && c.Cnf.JKT != "" {
+		return nil, ErrTokenBound
+	}
+
+	return newPrincipal(c), nil
+}
+
+// keyFunc pins the algorithm of the token to the one of its key
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := v.keys.key(kid)
+	if !ok {
+		return nil, ErrTokenUnknownKey
+	}
+
+	if token.Method.Alg() != key.alg {
+		return nil, ErrTokenAlgorithm
+	}
+
+	return key.publicKey, nil
+}
+
+// validationError maps the errors of jwt-go to the typed errors
+func validationError(err error) error {
+	validationErr, ok := err.(*jwt.ValidationError)
+	if !ok {
+		return ErrTokenMalformed
+	}
+
+	switch {
+	case validationErr.Inner == ErrTokenUnknownKey || validationErr.Inner == ErrTokenAlgorithm:
+		return validationErr.Inner
+	case validationErr.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+		return ErrTokenSignature
+	case validationErr.Errors&jwt.ValidationErrorUnverifiable != 0:
+		// jwt-go rejects the alg header before the key is resolved
+		return ErrTokenAlgorithm
+	}
+
+	return ErrTokenMalformed
+}
@@ -0,0 +1,30 @@
+package crypto
+
+import (
+	"testing"
+)
+
+func TestCipher(t *testing.T) {
+	c, err := NewCipher("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := c.Encrypt([]byte("secret"), []byte("kid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := c.Decrypt(ciphertext, []byte("kid"))
+	if err != nil || string(plaintext) != "secret" {
+		t.Errorf("got %q, %v want secret", plaintext, err)
+	}
+
+	if _, err = c.Decrypt(ciphertext, []byte("other")); err == nil {
+		t.Error("decrypted with other additional data")
+	}
+
+	if _, err = NewCipher("c2hvcnQ="); err == nil {
+		t.Error("accepted a short key")
+	}
+}
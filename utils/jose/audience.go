@@ -0,0 +1,34 @@
+package jose
+
+import "encoding/json"
+
+// Audience is the aud claim, a single string or an array of strings (RFC 7519, section 4.1.3)
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+
+	return nil
+}
+
+// Contains reports whether any of the values is an audience
+func (a Audience) Contains(values ...string) bool {
+	for _, aud := range a {
+		for _, value := range values {
+			if aud == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package jose
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestThumbprint(t *testing.T) {
+	// RFC 8037, Appendix A.3
+	jwk := JWK{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		X:   "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"; thumbprint != want {
+		t.Errorf("[Thumbprint] got %v want %v", thumbprint, want)
+	}
+}
+
+func TestSigningMethodEdDSA(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tokenString, err := jwt.NewWithClaims(SigningMethodEdDSA, jwt.MapClaims{"sub": "test"}).SignedString(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	})
+	if err != nil || !token.Valid {
+		t.Errorf("[EdDSA] token invalid: %v", err)
+	}
+
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	_, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return otherKey, nil
+	})
+	if err == nil {
+		t.Errorf("[EdDSA] token verified with a wrong key")
+	}
+}
+
+func TestJWKPublicKey(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	jwk, err := NewJWK(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := jwk.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !publicKey.Equal(decoded) {
+		t.Errorf("[PublicKey] got %v want %v", decoded, publicKey)
+	}
+}
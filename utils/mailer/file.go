@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileMailer appends the rendered messages to a file as JSON lines,
+// for local development and tests. Without a path it logs them.
+type fileMailer struct {
+	sync.Mutex
+	path string
+}
+
+// fileEntry is a line of the mail file
+type fileEntry struct {
+	Template  string    `json:"template"`
+	To        string    `json:"to"`
+	URL       string    `json:"url,omitempty"`
+	Locale    string    `json:"locale,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Priority  Priority  `json:"priority"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// NewFileMailer returns a mailer writing to path, or to the log if it is empty
+func NewFileMailer(path string) Mailer {
+	return &fileMailer{path: path}
+}
+
+func (m *fileMailer) Send(ctx context.Context, message Message) error {
+	subject, body, err := render(message)
+	if err != nil {
+		return err
+	}
+
+	entry := fileEntry{
+		Template:  message.Template,
+		To:        message.To,
+		URL:       message.URL,
+		Locale:    message.Locale,
+		RequestID: message.RequestID,
+		Priority:  message.Priority,
+		Subject:   subject,
+		Body:      body,
+		SentAt:    time.Now(),
+	}
+
+	if m.path == "" {
+		log.WithField("mail", entry).Info("Send mail")
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/middleware"
+
+	grpcClient "github.com/handymesh/hyshAuthService/grpc/client"
+	pb "github.com/handymesh/hyshAuthService/grpc/mail"
+)
+
+// grpcMailer sends through the mail service, which renders the templates
+type grpcMailer struct{}
+
+// NewGrpcMailer returns a mailer of the mail service at API_MAIL_ADDRESS
+func NewGrpcMailer() Mailer {
+	return &grpcMailer{}
+}
+
+var priorities = map[Priority]pb.Priority{
+	PRIORITY_LOW:    pb.Priority_LOW,
+	PRIORITY_NORMAL: pb.Priority_NORMAL,
+	PRIORITY_HIGH:   pb.Priority_HIGH,
+}
+
+func (m *grpcMailer) Send(ctx context.Context, message Message) error {
+	// The client sends the request id of ctx as metadata,
+	// messages from the outbox carry it themselves
+	if message.RequestID != "" && middleware.GetReqID(ctx) == "" {
+		ctx = context.WithValue(ctx, middleware.RequestIDKey, message.RequestID)
+	}
+
+	c := pb.NewMailClient(grpcClient.GetConnClient())
+	resp, err := c.SendMail(ctx, &pb.MailRequest{
+		Template:  message.Template,
+		Mail:      message.To,
+		Url:       message.URL,
+		Locale:    message.Locale,
+		Variables: message.Variables,
+		RequestId: message.RequestID,
+		Priority:  priorities[message.Priority],
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return ErrNotSent
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+const (
+	BACKEND_GRPC = "grpc"
+	BACKEND_SMTP = "smtp"
+	BACKEND_FILE = "file"
+
+	TEMPLATE_RECOVERY = "recovery"
+)
+
+var (
+	log = logrus.New()
+
+	// Default is the mailer selected by Setup
+	Default Mailer
+
+	ErrUnknownTemplate = errors.New("unknown mail template")
+	ErrNotSent         = errors.New("mail service failed to send the message")
+)
+
+func init() {
+	// Logging =================================================================
+	// Setup the logger backend using Sirupsen/logrus and configure
+	// it to use a custom JSONFormatter. See the logrus docs for how to
+	// configure the backend at github.com/Sirupsen/logrus
+	log.Formatter = new(logrus.JSONFormatter)
+}
+
+// Message is a mail rendered from a template in the locale of the recipient.
+// The outbox sends messages with the same IdempotencyKey only once.
+type Message struct {
+	Template  string
+	To        string
+	URL       string
+	Locale    string
+	Variables map[string]string
+	// RequestID of the request which caused the mail, for correlation
+	RequestID      string
+	Priority       Priority
+	IdempotencyKey string
+}
+
+// Priority orders the delivery from the outbox, higher first
+type Priority int
+
+const (
+	PRIORITY_LOW    Priority = -1
+	PRIORITY_NORMAL Priority = 0
+	PRIORITY_HIGH   Priority = 1
+)
+
+// Mailer sends messages
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// Setup selects the Default mailer by MAIL_BACKEND: "grpc" sends through
+// the mail service, "smtp" directly to SMTP_SERVER, and "file" appends
+// the messages to MAIL_FILE, or logs them if it is not set.
+// Unless MAIL_OUTBOX is "false", messages go through the outbox. Without
+// a valid MAIL_OUTBOX_ENCRYPTION_KEY they are sent directly instead.
+func Setup() {
+	// Get configuration
+	MAIL_BACKEND := utils.Getenv("MAIL_BACKEND", BACKEND_GRPC)
+	MAIL_OUTBOX := utils.Getenv("MAIL_OUTBOX", "true")
+
+	mailer, err := New(MAIL_BACKEND)
+	if err != nil {
+		log.Panic("Fail setup mailer: ", err)
+	}
+	log.Info("MAIL_BACKEND", " ", MAIL_BACKEND)
+
+	if MAIL_OUTBOX != "false" {
+		outbox, err := NewOutbox(mailer)
+		if err != nil {
+			log.Error("Mail outbox is disabled, mails are sent directly: ", err)
+		} else {
+			go outbox.Run()
+			mailer = outbox
+		}
+	}
+
+	Default = mailer
+}
+
+// New returns the mailer of a backend configured from the environment
+func New(backend string) (Mailer, error) {
+	switch backend {
+	case BACKEND_GRPC:
+		return NewGrpcMailer(), nil
+	case BACKEND_SMTP:
+		return NewSmtpMailer()
+	case BACKEND_FILE:
+		// Get configuration
+		MAIL_FILE := utils.Getenv("MAIL_FILE", "")
+
+		return NewFileMailer(MAIL_FILE), nil
+	}
+
+	return nil, errors.New("unknown mail backend " + backend)
+}
+
+// Send sends the message with the Default mailer
+func Send(ctx context.Context, message Message) error {
+	return Default.Send(ctx, message)
+}
@@ -0,0 +1,123 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.jsonl")
+	m := NewFileMailer(path)
+
+	err := m.Send(context.Background(), Message{
+		Template: TEMPLATE_RECOVERY,
+		To:       "user@example.com",
+		URL:      "http://localhost:3000/recovery/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry fileEntry
+	if err = json.Unmarshal(b, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.To != "user@example.com" || !strings.Contains(entry.Body, "http://localhost:3000/recovery/token") {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	err = m.Send(context.Background(), Message{Template: "unknown", To: "user@example.com"})
+	if err != ErrUnknownTemplate {
+		t.Errorf("got %v want %v", err, ErrUnknownTemplate)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	min, max := time.Second*10, time.Minute
+
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{1, time.Second * 10},
+		{2, time.Second * 20},
+		{3, time.Second * 40},
+		{4, time.Minute},
+		{20, time.Minute},
+	}
+
+	for _, test := range tests {
+		if delay := backoff(test.attempts, min, max); delay != test.delay {
+			t.Errorf("[%d attempts] got %v want %v", test.attempts, delay, test.delay)
+		}
+	}
+}
+
+func TestRenderLocale(t *testing.T) {
+	tests := []struct {
+		locale  string
+		subject string
+	}{
+		{"", "Password recovery"},
+		{"ru", "Восстановление пароля"},
+		{"ru-RU", "Восстановление пароля"},
+		{"de", "Password recovery"},
+	}
+
+	for _, test := range tests {
+		subject, body, err := render(Message{
+			Template:  TEMPLATE_RECOVERY,
+			URL:       "http://localhost:3000/recovery/token",
+			Locale:    test.locale,
+			Variables: map[string]string{"fullname": "Jane Doe"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subject != test.subject || !strings.Contains(body, "Jane Doe") {
+			t.Errorf("[%s] unexpected mail %q %q", test.locale, subject, body)
+		}
+	}
+}
+
+func TestSetupWithoutOutboxKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		outbox bool
+	}{
+		{"without key", "", false},
+		{"invalid key", "secretKey", false},
+		{"valid key", "8o49DO5Tbx9NetNKyHmzvO3ZlG6rlpfoG7C2/ljoYd4=", true},
+	}
+
+	for _, test := range tests {
+		t.Setenv("MAIL_OUTBOX_ENCRYPTION_KEY", test.key)
+
+		outbox, err := NewOutbox(NewFileMailer(""))
+		if (err == nil) != test.outbox {
+			t.Errorf("[%s] got %v want outbox %v", test.name, err, test.outbox)
+		}
+		if err == nil && outbox == nil {
+			t.Errorf("[%s] got no outbox", test.name)
+		}
+	}
+
+	// A deploy without the key still sends mails, directly
+	t.Setenv("MAIL_BACKEND", BACKEND_FILE)
+	t.Setenv("MAIL_OUTBOX_ENCRYPTION_KEY", "")
+	Setup()
+	if _, ok := Default.(*Outbox); ok || Default == nil {
+		t.Errorf("got mailer %T want the file backend", Default)
+	}
+}
@@ -0,0 +1,271 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	outboxModel "github.com/handymesh/hyshAuthService/models/outbox"
+	"github.com/handymesh/hyshAuthService/utils"
+	"github.com/handymesh/hyshAuthService/utils/crypto"
+)
+
+const (
+	// how long a claimed message is locked, longer than a delivery attempt
+	OUTBOX_LEASE          = time.Minute * 2
+	OUTBOX_SEND_TIMEOUT   = time.Second * 30
+	OUTBOX_PURGE_INTERVAL = time.Hour
+)
+
+// Outbox stores the messages in MongoDB and delivers them with the backend
+// in background, retrying with exponential backoff. A message failing
+// MAIL_MAX_ATTEMPTS times, or with an unknown template, is dead-lettered.
+// URLs, which may carry a token, are stored encrypted with MAIL_OUTBOX_ENCRYPTION_KEY.
+type Outbox struct {
+	backend      Mailer
+	cipher       *crypto.Cipher
+	pollInterval time.Duration
+	retryMin     time.Duration
+	retryMax     time.Duration
+	maxAttempts  int
+	retention    time.Duration
+}
+
+// NewOutbox returns an outbox of the backend configured from the environment.
+// It fails without a valid MAIL_OUTBOX_ENCRYPTION_KEY.
+func NewOutbox(backend Mailer) (*Outbox, error) {
+	// Get configuration
+	MAIL_OUTBOX_POLL_INTERVAL := utils.Getenv("MAIL_OUTBOX_POLL_INTERVAL", "1s")
+	MAIL_RETRY_MIN := utils.Getenv("MAIL_RETRY_MIN", "10s")
+	MAIL_RETRY_MAX := utils.Getenv("MAIL_RETRY_MAX", "1h")
+	MAIL_MAX_ATTEMPTS := utils.Getenv("MAIL_MAX_ATTEMPTS", "10")
+	MAIL_OUTBOX_RETENTION := utils.Getenv("MAIL_OUTBOX_RETENTION", "168h")
+	MAIL_OUTBOX_ENCRYPTION_KEY := utils.Getenv("MAIL_OUTBOX_ENCRYPTION_KEY", "")
+
+	outbox := &Outbox{backend: backend}
+
+	var err error
+	if outbox.cipher, err = crypto.NewCipher(MAIL_OUTBOX_ENCRYPTION_KEY); err != nil {
+		return nil, fmt.Errorf("incorrect MAIL_OUTBOX_ENCRYPTION_KEY, generate one with `openssl rand -base64 32`: %w", err)
+	}
+	if outbox.pollInterval, err = time.ParseDuration(MAIL_OUTBOX_POLL_INTERVAL); err != nil {
+		log.Panic("Incorrect MAIL_OUTBOX_POLL_INTERVAL: ", err)
+	}
+	if outbox.retryMin, err = time.ParseDuration(MAIL_RETRY_MIN); err != nil {
+		log.Panic("Incorrect MAIL_RETRY_MIN: ", err)
+	}
+	if outbox.retryMax, err = time.ParseDuration(MAIL_RETRY_MAX); err != nil {
+		log.Panic("Incorrect MAIL_RETRY_MAX: ", err)
+	}
+	if outbox.maxAttempts, err = strconv.Atoi(MAIL_MAX_ATTEMPTS); err != nil || outbox.maxAttempts < 1 {
+		log.Panic("Incorrect MAIL_MAX_ATTEMPTS: ", MAIL_MAX_ATTEMPTS)
+	}
+	if outbox.retention, err = time.ParseDuration(MAIL_OUTBOX_RETENTION); err != nil {
+		log.Panic("Incorrect MAIL_OUTBOX_RETENTION: ", err)
+	}
+
+	return outbox, nil
+}
+
+// Send enqueues the message, a message with a known IdempotencyKey is skipped
+func (o *Outbox) Send(ctx context.Context, message Message) error {
+	id := message.IdempotencyKey
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	// The id binds the URL to its message
+	var encryptedURL string
+	if message.URL != "" {
+		var err error
+		encryptedURL, err = o.cipher.Encrypt([]byte(message.URL), []byte(id))
+		if err != nil {
+			return err
+		}
+	}
+
+	added, err := outboxModel.Add(&outboxModel.Message{
+		Id:           id,
+		Template:     message.Template,
+		To:           message.To,
+		EncryptedURL: encryptedURL,
+		Locale:       message.Locale,
+		Variables:    message.Variables,
+		RequestID:    message.RequestID,
+		Priority:     int(message.Priority),
+		Trace:        injectTrace(ctx),
+	})
+	if err != nil {
+		return err
+	}
+	if !added {
+		log.Info("Skip mail ", message.IdempotencyKey, ", already in the outbox")
+	}
+
+	return nil
+}
+
+// Run delivers the due messages every poll interval. Any number
+// of replicas may run it, each message is claimed by one of them.
+func (o *Outbox) Run() {
+	poll := time.NewTicker(o.pollInterval)
+	purge := time.NewTicker(OUTBOX_PURGE_INTERVAL)
+	defer poll.Stop()
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-poll.C:
+			o.deliverDue()
+		case <-purge.C:
+			count, err := outboxModel.Purge(time.Now().Add(-o.retention))
+			if err != nil {
+				log.Error("Fail purge outbox: ", err)
+				continue
+			}
+			if count > 0 {
+				log.Info("Purge ", count, " sent mails from the outbox")
+			}
+		}
+	}
+}
+
+// deliverDue delivers messages until none is due
+func (o *Outbox) deliverDue() {
+	for {
+		message, err := outboxModel.Claim(OUTBOX_LEASE)
+		if err != nil {
+			log.Error("Fail claim outbox message: ", err)
+			return
+		}
+		if message == nil {
+			return
+		}
+
+		o.deliver(message)
+	}
+}
+
+func (o *Outbox) deliver(message *outboxModel.Message) {
+	span := startDeliverySpan(message)
+	defer span.Finish()
+
+	ctx, cancel := context.WithTimeout(opentracing.ContextWithSpan(context.Background(), span), OUTBOX_SEND_TIMEOUT)
+	defer cancel()
+
+	url, err := o.decryptURL(message)
+	if err != nil {
+		ext.Error.Set(span, true)
+		log.Error("Dead-letter mail ", message.Id, ", its URL can't be decrypted: ", err)
+		err = outboxModel.MarkDead(message.Id, message.ClaimToken, err.Error())
+		if err != nil {
+			log.Error("Fail update outbox message ", message.Id, ": ", err)
+		}
+		return
+	}
+
+	err = o.backend.Send(ctx, Message{
+		Template:       message.Template,
+		To:             message.To,
+		URL:            url,
+		Locale:         message.Locale,
+		Variables:      message.Variables,
+		RequestID:      message.RequestID,
+		Priority:       Priority(message.Priority),
+		IdempotencyKey: message.Id,
+	})
+	if err != nil {
+		ext.Error.Set(span, true)
+	}
+
+	switch {
+	case err == nil:
+		err = outboxModel.MarkSent(message.Id, message.ClaimToken)
+	case err == ErrUnknownTemplate || message.Attempts >= o.maxAttempts:
+		log.Error("Dead-letter mail ", message.Id, " after ", message.Attempts, " attempts: ", err)
+		err = outboxModel.MarkDead(message.Id, message.ClaimToken, err.Error())
+	default:
+		delay := backoff(message.Attempts, o.retryMin, o.retryMax)
+		log.Warn("Retry mail ", message.Id, " in ", delay, ": ", err)
+		err = outboxModel.Retry(message.Id, message.ClaimToken, time.Now().Add(delay), err.Error())
+	}
+	if err == outboxModel.ErrClaimLost {
+		log.Warn("Outcome of mail ", message.Id, " dropped, its lease expired: ", err)
+		return
+	}
+	if err != nil {
+		log.Error("Fail update outbox message ", message.Id, ": ", err)
+	}
+}
+
+// decryptURL returns the URL of the message, in plaintext for messages enqueued before encryption
+func (o *Outbox) decryptURL(message *outboxModel.Message) (string, error) {
+	if message.EncryptedURL == "" {
+		return message.URL, nil
+	}
+
+	url, err := o.cipher.Decrypt(message.EncryptedURL, []byte(message.Id))
+	if err != nil {
+		return "", err
+	}
+
+	return string(url), nil
+}
+
+// injectTrace returns the tracing context of ctx to store with a message
+func injectTrace(ctx context.Context) map[string]string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return nil
+	}
+
+	carrier := opentracing.TextMapCarrier{}
+	err := span.Tracer().Inject(span.Context(), opentracing.TextMap, carrier)
+	if err != nil {
+		log.Warn("Fail inject tracing context: ", err)
+		return nil
+	}
+
+	return carrier
+}
+
+// startDeliverySpan traces a delivery attempt, following
+// the span of the request which enqueued the message
+func startDeliverySpan(message *outboxModel.Message) opentracing.Span {
+	tracer := opentracing.GlobalTracer()
+
+	var opts []opentracing.StartSpanOption
+	if len(message.Trace) > 0 {
+		parent, err := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(message.Trace))
+		if err == nil {
+			opts = append(opts, opentracing.FollowsFrom(parent))
+		}
+	}
+
+	span := tracer.StartSpan("mail.deliver", opts...)
+	span.SetTag("outbox.message_id", message.Id)
+	span.SetTag("outbox.attempt", message.Attempts)
+	if message.RequestID != "" {
+		span.SetTag("request_id", message.RequestID)
+	}
+
+	return span
+}
+
+// backoff is the delay after the given number of failed attempts,
+// doubling from min up to max
+func backoff(attempts int, min time.Duration, max time.Duration) time.Duration {
+	delay := min
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
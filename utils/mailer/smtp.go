@@ -0,0 +1,158 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/handymesh/hyshAuthService/utils"
+)
+
+// smtpMailer renders the messages and sends them directly to an SMTP server
+type smtpMailer struct {
+	server   string
+	port     string
+	username string
+	password string
+	from     mail.Address
+}
+
+// NewSmtpMailer returns a mailer of SMTP_SERVER. Port 465 uses implicit TLS,
+// other ports upgrade with STARTTLS when the server offers it.
+func NewSmtpMailer() (Mailer, error) {
+	// Get configuration
+	SMTP_SERVER := utils.Getenv("SMTP_SERVER", "")
+	SMTP_PORT := utils.Getenv("SMTP_PORT", "465")
+	SMTP_USERNAME := utils.Getenv("SMTP_USERNAME", "")
+	SMTP_PASSWORD := utils.Getenv("SMTP_PASSWORD", "")
+	SMTP_FROM := utils.Getenv("SMTP_FROM", SMTP_USERNAME)
+
+	if SMTP_SERVER == "" {
+		return nil, errors.New("SMTP_SERVER is required")
+	}
+
+	from, err := mail.ParseAddress(SMTP_FROM)
+	if err != nil {
+		return nil, errors.New("incorrect SMTP_FROM: " + err.Error())
+	}
+
+	return &smtpMailer{
+		server:   SMTP_SERVER,
+		port:     SMTP_PORT,
+		username: SMTP_USERNAME,
+		password: SMTP_PASSWORD,
+		from:     *from,
+	}, nil
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message Message) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return err
+	}
+
+	subject, body, err := render(message)
+	if err != nil {
+		return err
+	}
+
+	c, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if m.username != "" {
+		err = c.Auth(smtp.PlainAuth("", m.username, m.password, m.server))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = c.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err = c.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + m.from.String() + "\r\n")
+	msg.WriteString("To: " + to.String() + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	if message.RequestID != "" {
+		msg.WriteString("X-Request-ID: " + strings.Map(headerRune, message.RequestID) + "\r\n")
+	}
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	if _, err = w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// headerRune drops the characters that could break out of a header line
+func headerRune(r rune) rune {
+	if r == '\r' || r == '\n' {
+		return -1
+	}
+	return r
+}
+
+// dial connects to the server within the deadline of ctx
+func (m *smtpMailer) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(m.server, m.port)
+	tlsConfig := &tls.Config{ServerName: m.server}
+
+	dialer := &net.Dialer{Timeout: time.Second * 30}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+	conn.SetDeadline(deadline)
+
+	if m.port == "465" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, m.server)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if ok, _ := c.Extension("STARTTLS"); ok && m.port != "465" {
+		err = c.StartTLS(tlsConfig)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
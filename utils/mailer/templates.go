@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+const DEFAULT_LOCALE = "en"
+
+// mailTemplate is rendered by the backends sending the mail themselves.
+// The mail service has its own templates.
+type mailTemplate struct {
+	subject string
+	body    *template.Template
+}
+
+// templates by name and locale
+var templates = map[string]map[string]mailTemplate{
+	TEMPLATE_RECOVERY: {
+		"en": {
+			subject: "Password recovery",
+			body: template.Must(template.New(TEMPLATE_RECOVERY).Parse(
+				"{{with .Variables.fullname}}Hello, {{.}}!\n\n{{end}}" +
+					"Follow the link to set a new password:\n\n{{.URL}}\n\n" +
+					"If you didn't ask to recover your password, ignore this mail.\n")),
+		},
+		"ru": {
+			subject: "Восстановление пароля",
+			body: template.Must(template.New(TEMPLATE_RECOVERY).Parse(
+				"{{with .Variables.fullname}}Здравствуйте, {{.}}!\n\n{{end}}" +
+					"Перейдите по ссылке, чтобы задать новый пароль:\n\n{{.URL}}\n\n" +
+					"Если вы не запрашивали восстановление пароля, проигнорируйте это письмо.\n")),
+		},
+	},
+}
+
+// render returns the subject and the body of a message. A locale without
+// a template falls back to its language, then to DEFAULT_LOCALE.
+func render(message Message) (string, string, error) {
+	locales, ok := templates[message.Template]
+	if !ok {
+		return "", "", ErrUnknownTemplate
+	}
+
+	tmpl, ok := locales[message.Locale]
+	if !ok {
+		language := strings.ToLower(strings.SplitN(strings.Replace(message.Locale, "_", "-", 1), "-", 2)[0])
+		tmpl, ok = locales[language]
+	}
+	if !ok {
+		tmpl = locales[DEFAULT_LOCALE]
+	}
+
+	var body bytes.Buffer
+	err := tmpl.body.Execute(&body, message)
+	if err != nil {
+		return "", "", err
+	}
+
+	return tmpl.subject, body.String(), nil
+}